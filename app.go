@@ -1,15 +1,31 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"restic-gui/internal/config"
+	"restic-gui/internal/history"
+	"restic-gui/internal/hooks"
+	"restic-gui/internal/i18n"
+	"restic-gui/internal/jobs"
+	"restic-gui/internal/netcost"
+	"restic-gui/internal/notify"
 	"restic-gui/internal/restic"
 
 	"github.com/google/uuid"
@@ -17,16 +33,229 @@ import (
 )
 
 type App struct {
-	ctx    context.Context
-	config *config.ConfigManager
-	runner *restic.Runner
+	ctx     context.Context
+	config  *config.ConfigManager
+	runner  restic.CommandRunner
+	console *console // nur ohne GUI (--run-backup): Ausgabe statt Events
+
+	restores  *history.Log[history.RestoreEntry]
+	backups   *history.Log[history.BackupEntry]
+	manifests *history.ManifestStore
+	jobs      *jobs.Queue
+
+	mu           sync.Mutex
+	enrichCancel context.CancelFunc         // laufende Snapshot-Größenabfrage
+	lsCancels    map[int]context.CancelFunc // laufende ListSnapshotContents-Aufrufe
+	nextLsID     int
+	ops          sync.WaitGroup           // laufende Hintergrund-Operationen
+	lsCache      []lsCacheEntry           // zuletzt gelesene Snapshot-Inhalte, neueste zuletzt
+	snapLists    map[string]snapListEntry // letzte "snapshots --json"-Ausgabe je Repository-ID
+	storage      *StorageUsage            // zuletzt ermittelte Gesamtbelegung
+	restoring    map[string]bool          // Manifest-IDs laufender Wiederherstellungen
+	checking     bool                     // CheckRepository läuft
+	version      *restic.VersionInfo
+	deleteTokens map[string]pendingDelete     // Bestätigungen aus PreviewDeleteSnapshot
+	repoStats    map[string]RepoStatus        // Ergebnisse des Hintergrund-Pollers
+	formats      map[string]restic.RepoConfig // "cat config" je Repository-URI
+	reachable    map[string]reachableEntry    // letzte erfolgreiche Verbindung je Repository-ID
+	lastAdded    map[string]uint64            // data_added des letzten Backups je Repository-ID
+	reclaimable  map[string]Reclaimable       // letzte prune --dry-run-Schätzung je Repository-ID
+	stopPoller   chan struct{}                // beendet pollStats und scheduleChecks
+}
+
+// lsCacheEntry hält die ls-Ausgabe eines Snapshots; Snapshots sind unveränderlich
+type lsCacheEntry struct {
+	key   string // Repository-URI + Snapshot-ID
+	nodes []restic.FileNode
+}
+
+// lsCacheSize begrenzt, wie viele Snapshot-Inhalte im Speicher bleiben
+const lsCacheSize = 3
+
+// snapListEntry hält die Snapshot-Liste eines Repositories für Folgeabfragen
+type snapListEntry struct {
+	uri string
+	out string
+	at  time.Time
+}
+
+// snapListMaxAge: so lange nutzen Kalender, Kurzliste und Datumssuche die
+// Liste des letzten GetSnapshots, statt restic erneut zu starten
+const snapListMaxAge = 30 * time.Second
+
+// BackupRetry wird als "backup:retry" gesendet, bevor ein Backup wiederholt wird
+type BackupRetry struct {
+	JobID        string `json:"jobId"`
+	Attempt      int    `json:"attempt"`
+	MaxRetries   int    `json:"maxRetries"`
+	DelaySeconds int    `json:"delaySeconds"`
+	Error        string `json:"error"`
 }
 
+// retryBaseDelay is the wait before the first retry; it doubles each attempt
+const retryBaseDelay = 10 * time.Second
+
 func NewApp() *App {
 	return &App{}
 }
 
-func (a *App) shutdown(ctx context.Context) {}
+// configPollInterval is how often config.json is checked for external changes
+const configPollInterval = 2 * time.Second
+
+// shutdownTimeout is how long shutdown waits for cancelled operations to exit
+const shutdownTimeout = 10 * time.Second
+
+// shutdown cancels every running operation, waits briefly for the restic
+// processes to exit so none are left orphaned, and flushes the config
+func (a *App) shutdown(ctx context.Context) {
+	if a.stopPoller != nil {
+		close(a.stopPoller)
+	}
+	a.cancelOperations()
+
+	done := make(chan struct{})
+	go func() {
+		a.ops.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		runtime.LogWarning(ctx, "Shutdown: operations still running after timeout")
+	}
+
+	if a.config != nil {
+		a.config.StopWatching()
+		if err := a.config.Save(); err != nil {
+			runtime.LogError(ctx, "Failed to save config on shutdown: "+err.Error())
+		}
+	}
+}
+
+// goOp runs fn in the background and tracks it so shutdown can wait for it
+func (a *App) goOp(fn func()) {
+	a.ops.Add(1)
+	go func() {
+		defer a.ops.Done()
+		fn()
+	}()
+}
+
+// emit sendet ein Event an die UI. Ohne GUI gibt es keinen Wails-Context;
+// dann schreibt die Konsole die für ein Protokoll wichtigen Events.
+// Alles, was auch --run-backup durchläuft, muss emit statt
+// runtime.EventsEmit verwenden.
+func (a *App) emit(event string, data interface{}) {
+	if a.console != nil {
+		a.console.event(event, data)
+		return
+	}
+	runtime.EventsEmit(a.ctx, event, data)
+}
+
+// logWarning schreibt ins Wails-Log bzw. ohne GUI nach stderr
+func (a *App) logWarning(msg string) {
+	if a.console != nil {
+		fmt.Fprintln(os.Stderr, msg)
+		return
+	}
+	runtime.LogWarning(a.ctx, msg)
+}
+
+// logInfo schreibt ins Wails-Log; ohne GUI entfällt es, die Konsole gibt
+// die zugehörigen Events bereits aus
+func (a *App) logInfo(msg string) {
+	if a.console == nil {
+		runtime.LogInfo(a.ctx, msg)
+	}
+}
+
+// ErrSafeMode is returned by operations that delete or overwrite data while
+// safe mode is on
+var ErrSafeMode = errors.New("not available in safe mode; turn off safe mode first")
+
+// GetSafeMode reports whether safe mode is on: backups, forget and prune
+// only run with --dry-run, other operations that delete or overwrite data
+// (deleting, protecting or unprotecting snapshots, deleting repositories,
+// restoring to the original location, overwriting an export archive,
+// updating restic) are refused.
+func (a *App) GetSafeMode() bool {
+	return a.config.GetSafeMode()
+}
+
+// SetSafeMode turns safe mode on or off and sends the new state as
+// "safemode:changed" so every page can show it.
+func (a *App) SetSafeMode(enabled bool) error {
+	if err := a.config.SetSafeMode(enabled); err != nil {
+		return err
+	}
+	a.emit("safemode:changed", enabled)
+	return nil
+}
+
+// refuseInSafeMode liefert ErrSafeMode, solange der Safe-Mode aktiv ist
+func (a *App) refuseInSafeMode() error {
+	if a.config.GetSafeMode() {
+		return ErrSafeMode
+	}
+	return nil
+}
+
+// ErrBusy is returned when a backup, restore or check is started while
+// another of these long operations is still active
+var ErrBusy = errors.New("another operation is running; wait for it to finish")
+
+// busy reports whether a backup, restore or check is queued or running
+func (a *App) busy() bool {
+	return a.restoreOrCheckActive() || a.jobsActive()
+}
+
+// jobsActive meldet wartende oder laufende Jobs (Backups)
+func (a *App) jobsActive() bool {
+	for _, info := range a.jobs.List() {
+		if info.State == jobs.StateQueued || info.State == jobs.StateRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreOrCheckActive: Backups reihen sich in der Job-Queue ein und
+// dürfen deshalb mit weiteren Backups zusammentreffen
+func (a *App) restoreOrCheckActive() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.restoring) > 0 || a.checking
+}
+
+// domReady restores the window bounds saved on the last close
+func (a *App) domReady(ctx context.Context) {
+	state := a.config.GetUIState()
+	if state.WindowWidth > 0 && state.WindowHeight > 0 {
+		runtime.WindowSetSize(ctx, state.WindowWidth, state.WindowHeight)
+		runtime.WindowSetPosition(ctx, state.WindowX, state.WindowY)
+	}
+	if state.Maximised {
+		runtime.WindowMaximise(ctx)
+	}
+	if reminders := a.GetCheckReminders(defaultCheckReminderDays); len(reminders) > 0 {
+		runtime.EventsEmit(ctx, "check:reminders", reminders)
+	}
+}
+
+// beforeClose remembers the window bounds; it never prevents closing
+func (a *App) beforeClose(ctx context.Context) bool {
+	state := a.config.GetUIState()
+	state.Maximised = runtime.WindowIsMaximised(ctx)
+	if !state.Maximised && !runtime.WindowIsMinimised(ctx) {
+		state.WindowWidth, state.WindowHeight = runtime.WindowGetSize(ctx)
+		state.WindowX, state.WindowY = runtime.WindowGetPosition(ctx)
+	}
+	if err := a.config.SetUIState(state); err != nil {
+		runtime.LogWarning(ctx, "Failed to save UI state: "+err.Error())
+	}
+	return false
+}
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
@@ -36,12 +265,35 @@ func (a *App) startup(ctx context.Context) {
 		runtime.LogError(ctx, "Config error: "+err.Error())
 	}
 	a.config = cm
+	cm.Watch(configPollInterval, func() {
+		runtime.EventsEmit(a.ctx, "config:reloaded", nil)
+	})
+	a.jobs = jobs.NewQueue(cm.GetMaxConcurrentJobs(), func(event string, job jobs.Info) {
+		runtime.EventsEmit(a.ctx, event, job)
+	})
+	a.restores = history.NewLog[history.RestoreEntry](cm.Dir(), "restore-history.json")
+	a.backups = history.NewLog[history.BackupEntry](cm.Dir(), "backup-history.json")
+	a.manifests = history.NewManifestStore(filepath.Join(cm.Dir(), "restores"))
+	for _, w := range cm.Warnings() {
+		runtime.LogWarning(ctx, "Config: "+w)
+	}
+
+	i18n.SetLanguage(cm.GetLanguage())
+	if err := restic.LoadErrorRules(filepath.Join(cm.Dir(), "error-rules.json")); err != nil {
+		runtime.LogWarning(ctx, "Custom error rules ignored: "+err.Error())
+	}
 
 	runner, err := restic.NewRunner()
 	if err != nil {
 		runtime.LogWarning(ctx, "restic not found: "+err.Error())
+	} else {
+		// nur zuweisen, wenn gefunden: ein nil-*Runner im Interface wäre != nil
+		runner.SetMaxOutput(cm.MaxOutputBytes())
+		a.runner = runner
+		a.stopPoller = make(chan struct{})
+		go a.pollStats(a.stopPoller)
+		go a.scheduleChecks(a.stopPoller)
 	}
-	a.runner = runner
 }
 
 // ── Repository API ────────────────────────────────────────────────
@@ -50,144 +302,3682 @@ func (a *App) GetRepositories() []config.Repository {
 	return a.config.GetRepositories()
 }
 
-func (a *App) AddRepository(repo config.Repository) error {
+// FindRepositories returns the repositories matching query case-insensitively.
+// Every word of query must occur in the name, the URI or a metadata key or
+// value; repositories whose name starts with query come first. An empty
+// query returns all repositories.
+func (a *App) FindRepositories(query string) []config.Repository {
+	repos := a.config.GetRepositories()
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return repos
+	}
+	prefix := strings.ToLower(strings.TrimSpace(query))
+	matches := []config.Repository{}
+	for _, r := range repos {
+		if repoMatches(r, terms) {
+			matches = append(matches, r)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return strings.HasPrefix(strings.ToLower(matches[i].Name), prefix) &&
+			!strings.HasPrefix(strings.ToLower(matches[j].Name), prefix)
+	})
+	return matches
+}
+
+// repoMatches: jeder Suchbegriff muss in Name, URI oder Metadaten vorkommen.
+// Passwörter und Umgebungsvariablen werden bewusst nicht durchsucht.
+func repoMatches(r config.Repository, terms []string) bool {
+	fields := []string{strings.ToLower(r.Name), strings.ToLower(r.URI)}
+	for k, v := range r.Metadata {
+		fields = append(fields, strings.ToLower(k), strings.ToLower(v))
+	}
+	for _, t := range terms {
+		found := false
+		for _, f := range fields {
+			if strings.Contains(f, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// minPasswordLength ist die Länge, unter der ein Passwort als schwach gilt
+const minPasswordLength = 8
+
+// AddRepository stores a new repository. The returned warnings (e.g. about an
+// empty or short password) don't block saving but should be shown to the user.
+func (a *App) AddRepository(repo config.Repository) ([]string, error) {
+	if err := validateRepository(repo); err != nil {
+		return nil, err
+	}
 	repo.ID = uuid.New().String()
-	return a.config.AddRepository(repo)
+	if err := a.config.AddRepository(repo); err != nil {
+		return nil, err
+	}
+	return append(passwordWarnings(repo.Password), excludeWarnings(repo.Excludes)...), nil
+}
+
+// CheckExcludes lists exclude patterns that probably don't match what the
+// user meant, e.g. "C:\temp\" with a trailing separator, together with
+// the normalized form StartBackup will use instead
+func (a *App) CheckExcludes(patterns []string) []restic.ExcludeIssue {
+	return restic.CheckExcludes(patterns)
+}
+
+// excludeWarnings beschreibt die Probleme von CheckExcludes als Text
+func excludeWarnings(patterns []string) []string {
+	var warnings []string
+	for _, issue := range restic.CheckExcludes(patterns) {
+		if issue.Normalized == "" {
+			warnings = append(warnings, fmt.Sprintf("Exclude %q is ignored (%s)", issue.Pattern, strings.Join(issue.Reasons, ", ")))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Exclude %q is used as %q (%s)", issue.Pattern, issue.Normalized, strings.Join(issue.Reasons, ", ")))
+		}
+	}
+	return warnings
+}
+
+// validateRepository prüft die Einstellungen, die an restic weitergegeben werden
+func validateRepository(repo config.Repository) error {
+	if err := restic.ValidateExtraArgs(repo.ExtraArgs); err != nil {
+		return err
+	}
+	if err := restic.ValidateEnv(repo.Env); err != nil {
+		return err
+	}
+	if !restic.Priority(repo.Priority).Valid() {
+		return fmt.Errorf("invalid priority %q (allowed: low, idle)", repo.Priority)
+	}
+	if repo.CacheDir != "" && !filepath.IsAbs(repo.CacheDir) {
+		return fmt.Errorf("cache directory must be an absolute path: %s", repo.CacheDir)
+	}
+	if repo.CheckIntervalDays < 0 {
+		return fmt.Errorf("check interval must not be negative")
+	}
+	if repo.CheckReadDataSubset != "" {
+		if err := restic.ValidateReadDataSubset(repo.CheckReadDataSubset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackendConfig ist das Ergebnis von BuildBackend
+type BackendConfig struct {
+	URI string            `json:"uri"`
+	Env map[string]string `json:"env"`
+}
+
+// BuildBackend assembles the repository URI and environment variables from
+// the structured form of a backend (S3 endpoint, bucket, keys, …)
+func (a *App) BuildBackend(params restic.BackendParams) (BackendConfig, error) {
+	uri, env, err := params.Build()
+	if err != nil {
+		return BackendConfig{}, err
+	}
+	return BackendConfig{URI: uri, Env: env}, nil
+}
+
+// GetBackendParams splits a repository's URI and environment back into the
+// fields of the structured backend form
+func (a *App) GetBackendParams(repo config.Repository) restic.BackendParams {
+	return restic.ParseBackend(repo.BackendType, repo.URI, repo.Env)
+}
+
+// passwordWarnings prüft das Passwort auf offensichtliche Schwächen
+func passwordWarnings(password string) []string {
+	switch {
+	case password == "":
+		return []string{"The repository password is empty. Anyone with access to the storage can read your backups."}
+	case len([]rune(password)) < minPasswordLength:
+		return []string{fmt.Sprintf("The repository password is shorter than %d characters and easy to guess.", minPasswordLength)}
+	}
+	return nil
 }
 
 func (a *App) UpdateRepository(repo config.Repository) error {
+	if err := validateRepository(repo); err != nil {
+		return err
+	}
 	return a.config.UpdateRepository(repo)
 }
 
+// RenameRepository changes the name of a repository and nothing else
+func (a *App) RenameRepository(id, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("repository name must not be empty")
+	}
+	return a.config.RenameRepository(id, newName)
+}
+
+// DuplicateRepository saves a copy of a repository's settings under a new ID
+// and the name "Copy of <name>". URI and password are left empty, so the
+// destination has to be set before the copy can be used; backend type and
+// environment (e.g. S3 credentials) are copied.
+func (a *App) DuplicateRepository(id string) (config.Repository, error) {
+	repo, ok := a.config.GetRepository(id)
+	if !ok {
+		return config.Repository{}, fmt.Errorf("repository not found")
+	}
+	dup := config.Repository{
+		ID:            uuid.New().String(),
+		Name:          "Copy of " + repo.Name,
+		SourceFolders: append([]string{}, repo.SourceFolders...),
+		Excludes:      append([]string{}, repo.Excludes...),
+		ExcludeFiles:  append([]string{}, repo.ExcludeFiles...),
+		ExtraArgs:     append([]string{}, repo.ExtraArgs...),
+		DefaultTags:   append([]string{}, repo.DefaultTags...),
+		BackupRetries: repo.BackupRetries,
+		Priority:      repo.Priority,
+		BackendType:   repo.BackendType,
+		CacheDir:      repo.CacheDir,
+
+		PreBackupCommand:   repo.PreBackupCommand,
+		PostBackupCommand:  repo.PostBackupCommand,
+		IgnorePreBackupErr: repo.IgnorePreBackupErr,
+
+		CheckIntervalDays:   repo.CheckIntervalDays,
+		CheckReadDataSubset: repo.CheckReadDataSubset,
+
+		MaxRepoSize:   repo.MaxRepoSize,
+		QuotaWarnOnly: repo.QuotaWarnOnly,
+	}
+	if repo.Metadata != nil {
+		dup.Metadata = make(map[string]string, len(repo.Metadata))
+		for k, v := range repo.Metadata {
+			dup.Metadata[k] = v
+		}
+	}
+	if repo.Env != nil {
+		dup.Env = make(map[string]string, len(repo.Env))
+		for k, v := range repo.Env {
+			dup.Env[k] = v
+		}
+	}
+	if err := a.config.AddRepository(dup); err != nil {
+		return config.Repository{}, err
+	}
+	return dup, nil
+}
+
 func (a *App) DeleteRepository(id string) error {
+	// Mit dem Eintrag ginge auch das gespeicherte Passwort verloren
+	if err := a.refuseInSafeMode(); err != nil {
+		return err
+	}
 	return a.config.DeleteRepository(id)
 }
 
-func (a *App) SetLastUsedRepo(id string) {
-	a.config.SetLastUsedRepo(id)
+// GetRepositoryMetadata returns the key/value annotations of a repository
+func (a *App) GetRepositoryMetadata(id string) (map[string]string, error) {
+	repo, ok := a.config.GetRepository(id)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	if repo.Metadata == nil {
+		return map[string]string{}, nil
+	}
+	return repo.Metadata, nil
+}
+
+// SetRepositoryMetadata replaces the key/value annotations of a repository;
+// entries with an empty key or value are dropped
+func (a *App) SetRepositoryMetadata(id string, metadata map[string]string) error {
+	clean := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if k != "" && v != "" {
+			clean[k] = v
+		}
+	}
+	return a.config.SetMetadata(id, clean)
+}
+
+// SetLastUsedRepo records a repository the user picked, e.g. in the
+// snapshot list, and moves it to the front of the recent repositories
+func (a *App) SetLastUsedRepo(id string) error {
+	if err := a.config.SetLastUsedRepo(id); err != nil {
+		return err
+	}
+	return a.config.TouchRecentRepo(id)
+}
+
+// touchRecentRepo setzt repoID an den Anfang der zuletzt verwendeten
+// Repositories; ein Schreibfehler soll die eigentliche Aktion nicht abbrechen
+func (a *App) touchRecentRepo(repoID string) {
+	if err := a.config.TouchRecentRepo(repoID); err != nil {
+		a.logWarning("Could not update recent repositories: " + err.Error())
+	}
+}
+
+// GetRecentRepositories returns up to five repositories that were used most
+// recently (backup, restore, check or selected via SetLastUsedRepo), most
+// recent first
+func (a *App) GetRecentRepositories() []config.Repository {
+	return a.config.GetRecentRepos()
+}
+
+// GetUIState returns the last active tab, selected repository and window bounds
+func (a *App) GetUIState() config.UIState {
+	return a.config.GetUIState()
+}
+
+// SaveUIState stores the navigation state; window bounds are kept from the
+// last close because the frontend cannot read them reliably
+func (a *App) SaveUIState(state config.UIState) error {
+	current := a.config.GetUIState()
+	state.WindowX, state.WindowY = current.WindowX, current.WindowY
+	state.WindowWidth, state.WindowHeight = current.WindowWidth, current.WindowHeight
+	state.Maximised = current.Maximised
+	return a.config.SetUIState(state)
+}
+
+// ConfigLocation beschreibt, wo die Konfiguration gespeichert wird
+type ConfigLocation struct {
+	Path     string   `json:"path"`
+	Fallback bool     `json:"fallback"` // Benutzerverzeichnis nicht beschreibbar, Temp-Verzeichnis verwendet
+	Warnings []string `json:"warnings"`
+}
+
+// GetConfigLocation returns the config file actually in use and the
+// problems found with the config directory or file permissions
+func (a *App) GetConfigLocation() ConfigLocation {
+	return ConfigLocation{
+		Path:     a.config.Path(),
+		Fallback: a.config.IsFallback(),
+		Warnings: a.config.Warnings(),
+	}
 }
 
-func (a *App) TestRepository(id string) (string, error) {
+// TestRepository connects to the repository and returns its config
+// (format version, chunker polynomial and repository ID). The ID is stored
+// on first contact; if the location later holds a different repository,
+// an error is returned and the stored ID is kept.
+func (a *App) TestRepository(id string) (restic.RepoConfig, error) {
 	if a.runner == nil {
-		return "", fmt.Errorf("restic not found")
+		return restic.RepoConfig{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(id)
+	if !ok {
+		return restic.RepoConfig{}, fmt.Errorf("repository not found")
+	}
+	cfg, err := a.repoConfig(repo)
+	if err != nil {
+		return restic.RepoConfig{}, err
+	}
+	switch {
+	case repo.ResticRepoID == "":
+		if err := a.config.SetLocation(repo.ID, repo.URI, cfg.ID); err != nil {
+			runtime.LogWarning(a.ctx, "Failed to save repository ID: "+err.Error())
+		}
+	case cfg.ID != repo.ResticRepoID:
+		return restic.RepoConfig{}, fmt.Errorf("the repository at %s is a different repository (ID %s, expected %s)", repo.URI, shortID(cfg.ID), shortID(repo.ResticRepoID))
+	}
+	return cfg, nil
+}
+
+// ClearCache deletes the local restic cache of one repository; restic
+// rebuilds it on the next access. Only the repository's own subdirectory is
+// removed, so other repositories sharing the cache directory keep theirs.
+func (a *App) ClearCache(repoID string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	if a.busy() {
+		return ErrBusy
+	}
+	base := repo.CacheDir
+	if base == "" {
+		base = restic.CacheDir()
+	}
+	if base == "" {
+		return fmt.Errorf("cache directory not found")
+	}
+	// restic legt den Cache unter <cache-dir>/<Repository-ID> an
+	resticID := repo.ResticRepoID
+	if resticID == "" {
+		cfg, err := a.repoConfig(repo)
+		if err != nil {
+			return err
+		}
+		resticID = cfg.ID
+	}
+	if resticID == "" || strings.ContainsAny(resticID, `/\.`) {
+		return fmt.Errorf("invalid repository ID %q", resticID)
+	}
+	return os.RemoveAll(filepath.Join(base, resticID))
+}
+
+// repoConfig runs "restic cat config" against repo and caches the result
+// for GetRepositoryFormat
+func (a *App) repoConfig(repo config.Repository) (restic.RepoConfig, error) {
+	out, err := a.runner.Run(resticRepo(repo), []string{"cat", "config"})
+	var cfg restic.RepoConfig
+	if err == nil {
+		cfg, err = parseRepoConfig(out)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil {
+		delete(a.reachable, repo.ID)
+		return cfg, err
+	}
+	if a.formats == nil {
+		a.formats = map[string]restic.RepoConfig{}
+	}
+	a.formats[repo.URI] = cfg
+	if a.reachable == nil {
+		a.reachable = map[string]reachableEntry{}
+	}
+	a.reachable[repo.ID] = reachableEntry{uri: repo.URI, at: time.Now()}
+	return cfg, nil
+}
+
+// reachableEntry merkt sich, wann ein Repository zuletzt erreichbar war
+type reachableEntry struct {
+	uri string // bei geänderter URI gilt der Eintrag nicht mehr
+	at  time.Time
+}
+
+// Reachability ist das Ergebnis von GetRepositoryReachability
+type Reachability struct {
+	Reachable bool      `json:"reachable"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Cached    bool      `json:"cached"` // true = aus dem Cache, restic wurde nicht gestartet
+}
+
+// defaultReachabilityTTL gilt, wenn GetRepositoryReachability kein maxAge bekommt
+const defaultReachabilityTTL = 5 * time.Minute
+
+// GetRepositoryReachability reports whether a repository can be opened,
+// reusing the last successful connection if it is at most maxAgeSeconds old
+// (0 = 5 minutes). Failures are never cached, so the next call tests again.
+func (a *App) GetRepositoryReachability(repoID string, maxAgeSeconds int) (Reachability, error) {
+	if a.runner == nil {
+		return Reachability{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return Reachability{}, fmt.Errorf("repository not found")
+	}
+	maxAge := time.Duration(maxAgeSeconds) * time.Second
+	if maxAge <= 0 {
+		maxAge = defaultReachabilityTTL
+	}
+	a.mu.Lock()
+	entry, cached := a.reachable[repo.ID]
+	a.mu.Unlock()
+	if cached && entry.uri == repo.URI && time.Since(entry.at) <= maxAge {
+		return Reachability{Reachable: true, CheckedAt: entry.at, Cached: true}, nil
+	}
+	if _, err := a.repoConfig(repo); err != nil {
+		return Reachability{Reachable: false, Error: err.Error(), CheckedAt: time.Now()}, nil
+	}
+	return Reachability{Reachable: true, CheckedAt: time.Now()}, nil
+}
+
+// LockInfo ist ein Repository-Lock mit seinem Alter für die UI
+type LockInfo struct {
+	restic.Lock
+	AgeSeconds int64 `json:"ageSeconds"`
+	Stale      bool  `json:"stale"` // älter als restic.StaleLockAge
+}
+
+// GetLocks lists the locks currently held on a repository and how long ago
+// each was last refreshed. There is no manual keep-alive: restic refreshes
+// the locks of its running commands (including long restores) every five
+// minutes by itself, so a lock older than 30 minutes belongs to a process
+// that is gone or suspended.
+func (a *App) GetLocks(repoID string) ([]LockInfo, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	// --no-lock, sonst taucht der eigene Lock dieser Abfrage in der Liste auf
+	out, err := a.runner.Run(resticRepo(repo), []string{"--no-lock", "list", "locks"})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	locks := []LockInfo{}
+	for _, id := range restic.ParseIDs(out) {
+		raw, err := a.runner.Run(resticRepo(repo), []string{"--no-lock", "cat", "lock", id})
+		if err != nil {
+			continue // inzwischen freigegeben
+		}
+		l, err := restic.ParseLock(id, raw)
+		if err != nil {
+			return nil, err
+		}
+		age := now.Sub(l.Time)
+		locks = append(locks, LockInfo{Lock: l, AgeSeconds: int64(age.Seconds()), Stale: age > restic.StaleLockAge})
+	}
+	return locks, nil
+}
+
+// RepoFormat beschreibt das Format eines Repositories für die UI
+type RepoFormat struct {
+	Version           int    `json:"version"`
+	ID                string `json:"id"`
+	ChunkerPolynomial string `json:"chunkerPolynomial"`
+	Compression       bool   `json:"compression"` // Format v2: --compression möglich
+}
+
+// GetRepositoryFormat returns the repository's format version and identity
+// from "restic cat config". The result is cached per repository location;
+// refresh forces a new query.
+func (a *App) GetRepositoryFormat(repoID string, refresh bool) (RepoFormat, error) {
+	if a.runner == nil {
+		return RepoFormat{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return RepoFormat{}, fmt.Errorf("repository not found")
+	}
+	a.mu.Lock()
+	cfg, cached := a.formats[repo.URI]
+	a.mu.Unlock()
+	if !cached || refresh {
+		var err error
+		if cfg, err = a.repoConfig(repo); err != nil {
+			return RepoFormat{}, err
+		}
+	}
+	return RepoFormat{
+		Version:           cfg.Version,
+		ID:                cfg.ID,
+		ChunkerPolynomial: cfg.ChunkerPolynomial,
+		Compression:       cfg.SupportsCompression(),
+	}, nil
+}
+
+// RelocateRepository points a repository entry at a new URI, e.g. after the
+// NAS got a new address. The new location must contain the same restic
+// repository (same ID) as the old one, otherwise nothing is changed.
+func (a *App) RelocateRepository(id, newURI string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(id)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	newURI = strings.TrimSpace(newURI)
+	if newURI == "" {
+		return fmt.Errorf("no URI given")
+	}
+
+	// Erwartete ID: gespeichert oder, falls noch unbekannt, vom alten Ort
+	expected := repo.ResticRepoID
+	if expected == "" {
+		cfg, err := a.repoConfig(repo)
+		if err != nil {
+			return fmt.Errorf("the ID of this repository is unknown and the old location is unreachable: %w", err)
+		}
+		expected = cfg.ID
+	}
+
+	moved := repo
+	moved.URI = newURI
+	cfg, err := a.repoConfig(moved)
+	if err != nil {
+		return err
+	}
+	if cfg.ID != expected {
+		return fmt.Errorf("the repository at %s is a different repository (ID %s, expected %s)", newURI, shortID(cfg.ID), shortID(expected))
+	}
+	return a.config.SetLocation(repo.ID, newURI, cfg.ID)
+}
+
+// shortID kürzt eine Repository-ID für Meldungen wie restic selbst
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// parseRepoConfig liest das JSON-Objekt aus der Ausgabe von cat config;
+// eventuelle Warnzeilen davor oder danach werden ignoriert.
+func parseRepoConfig(out string) (restic.RepoConfig, error) {
+	var cfg restic.RepoConfig
+	start, end := strings.Index(out, "{"), strings.LastIndex(out, "}")
+	if start < 0 || end < start {
+		return cfg, fmt.Errorf("failed to parse repository config")
+	}
+	if err := json.Unmarshal([]byte(out[start:end+1]), &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse repository config")
+	}
+	return cfg, nil
+}
+
+// CheckRepository runs "restic check" and remembers when and with which
+// result the repository was last verified. A non-empty subset ("10%",
+// "1/12" or a size like "2G") also reads that part of the pack data via
+// --read-data-subset. Progress is sent as "check:progress".
+func (a *App) CheckRepository(id, subset string) (string, error) {
+	if a.runner == nil {
+		return "", fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(id)
+	if !ok {
+		return "", fmt.Errorf("repository not found")
+	}
+	a.touchRecentRepo(repo.ID)
+	return a.runCheck(repo, subset)
+}
+
+// runCheck runs restic check (with --read-data-subset if subset is set),
+// stores the result and sends a notification
+func (a *App) runCheck(repo config.Repository, subset string) (string, error) {
+	args, err := restic.CheckArgs(subset)
+	if err != nil {
+		return "", err
+	}
+	// Prüfen und markieren in einem kritischen Abschnitt, siehe beginRestore
+	a.mu.Lock()
+	if a.checking || len(a.restoring) > 0 || a.jobsActive() {
+		a.mu.Unlock()
+		return "", ErrBusy
+	}
+	a.checking = true
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.checking = false
+		a.mu.Unlock()
+	}()
+	// check schreibt Fortschritt und gefundene Fehler nach stderr
+	var out strings.Builder
+	err = a.runner.RunWithProgress(resticRepo(repo).WithProgress().WithStderrLines(), args, func(line string) {
+		p, ok := restic.ParseCheckLine(line)
+		if !ok {
+			return
+		}
+		runtime.EventsEmit(a.ctx, "check:progress", p)
+		if p.Percent < 0 {
+			out.WriteString(strings.TrimSpace(line) + "\n")
+		}
+	})
+	result := "ok"
+	if err != nil {
+		result = err.Error()
+	}
+	if saveErr := a.config.SetCheckResult(repo.ID, time.Now(), result); saveErr != nil {
+		runtime.LogWarning(a.ctx, "Failed to save check result: "+saveErr.Error())
+	}
+	a.notify("check", repo.Name, err, "")
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// ScheduledCheck wird nach jeder automatischen Prüfung als "check:finished" gesendet
+type ScheduledCheck struct {
+	RepoID   string    `json:"repoId"`
+	RepoName string    `json:"repoName"`
+	Subset   string    `json:"subset"`
+	Time     time.Time `json:"time"`
+	Output   string    `json:"output"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// checkStagger: Abstand zwischen zwei automatischen Prüfungen, damit fällige
+// Repositories nicht alle gleichzeitig Daten lesen
+const checkStagger = 10 * time.Minute
+
+// scheduleChecks runs restic check for every repository whose
+// CheckIntervalDays have passed since its last check, one at a time,
+// until stop is closed
+func (a *App) scheduleChecks(stop <-chan struct{}) {
+	wait := pollIdleCheck
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+		wait = pollIdleCheck
+		repo, ok := a.dueCheck()
+		if !ok || a.busy() {
+			continue
+		}
+		out, err := a.runCheck(repo, repo.CheckReadDataSubset)
+		if errors.Is(err, ErrBusy) {
+			continue
+		}
+		result := ScheduledCheck{
+			RepoID:   repo.ID,
+			RepoName: repo.Name,
+			Subset:   repo.CheckReadDataSubset,
+			Time:     time.Now(),
+			Output:   out,
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		runtime.EventsEmit(a.ctx, "check:finished", result)
+		wait = checkStagger
+	}
+}
+
+// dueCheck liefert das Repository, dessen automatische Prüfung am längsten fällig ist
+func (a *App) dueCheck() (config.Repository, bool) {
+	var due config.Repository
+	var dueSince time.Duration
+	found := false
+	for _, repo := range a.config.GetRepositories() {
+		if repo.CheckIntervalDays <= 0 {
+			continue
+		}
+		overdue := time.Since(repo.LastCheckTime) - time.Duration(repo.CheckIntervalDays)*24*time.Hour
+		if overdue >= 0 && (!found || overdue > dueSince) {
+			due, dueSince, found = repo, overdue, true
+		}
+	}
+	return due, found
+}
+
+// CheckReminder names a repository whose last integrity check is overdue
+type CheckReminder struct {
+	RepoID        string    `json:"repoId"`
+	RepoName      string    `json:"repoName"`
+	LastCheckTime time.Time `json:"lastCheckTime"`
+	DaysSince     int       `json:"daysSince"` // -1 = never checked
+}
+
+// defaultCheckReminderDays is the staleness used for the startup reminder
+const defaultCheckReminderDays = 30
+
+// GetCheckReminders lists repositories not checked within the last days days
+func (a *App) GetCheckReminders(days int) []CheckReminder {
+	reminders := []CheckReminder{}
+	for _, repo := range a.config.GetRepositories() {
+		if repo.LastCheckTime.IsZero() {
+			reminders = append(reminders, CheckReminder{RepoID: repo.ID, RepoName: repo.Name, DaysSince: -1})
+			continue
+		}
+		since := int(time.Since(repo.LastCheckTime).Hours() / 24)
+		if since >= days {
+			reminders = append(reminders, CheckReminder{
+				RepoID:        repo.ID,
+				RepoName:      repo.Name,
+				LastCheckTime: repo.LastCheckTime,
+				DaysSince:     since,
+			})
+		}
+	}
+	return reminders
+}
+
+// InitRepository runs "restic init". With copyChunkerParamsFrom set to a
+// repository ID, the new repository gets that repository's chunker
+// parameters so "restic copy" between them keeps deduplication.
+func (a *App) InitRepository(repo config.Repository, copyChunkerParamsFrom string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	target := resticRepo(repo)
+	args := []string{"init"}
+	// Gleiche Chunker-Parameter wie das Quell-Repository, sonst wird bei
+	// "restic copy" alles neu zerlegt und die Deduplizierung greift nicht
+	if copyChunkerParamsFrom != "" {
+		src, ok := a.config.GetRepository(copyChunkerParamsFrom)
+		if !ok {
+			return fmt.Errorf("source repository not found")
+		}
+		if v, err := a.resticVersion(); err == nil && !v.Supports(restic.FeatureFromRepo) {
+			return fmt.Errorf("restic %s does not support --from-repo, please update restic", v.Version)
+		}
+		fromURI, err := restic.ExpandURI(src.URI)
+		if err != nil {
+			return err
+		}
+		env, err := mergeFromRepoEnv(repo.Env, src.Env)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--copy-chunker-params", "--from-repo="+fromURI)
+		target.FromPassword = src.Password
+		target.Env = env
+	}
+	_, err := a.runner.Run(target, args)
+	return err
+}
+
+// mergeFromRepoEnv vereint die Umgebung von Ziel- und Quell-Repository für
+// --from-repo: restic liest beide aus demselben Prozess, daher braucht es auch
+// die Zugangsdaten der Quelle. Verlangen beide unterschiedliche Werte für
+// dieselbe Variable, kann ein einzelner Aufruf nicht beide bedienen.
+func mergeFromRepoEnv(target, source map[string]string) (map[string]string, error) {
+	env := make(map[string]string, len(target)+len(source))
+	for name, value := range target {
+		env[name] = value
+	}
+	for name, value := range source {
+		if existing, ok := env[name]; ok && existing != value {
+			return nil, fmt.Errorf("both repositories set %s to different values, restic can only use one", name)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// GetMigrations lists the format migrations restic can apply to a repository
+func (a *App) GetMigrations(repoID string) ([]restic.Migration, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	if v, err := a.resticVersion(); err == nil && !v.Supports(restic.FeatureRepoV2) {
+		return nil, fmt.Errorf("restic %s does not support repository format v2, please update restic", v.Version)
+	}
+	out, err := a.runner.Run(resticRepo(repo), []string{"migrate"})
+	if err != nil {
+		return nil, err
+	}
+	return restic.ParseMigrations(out), nil
+}
+
+// MigrateRepository applies one migration listed by GetMigrations. This is
+// irreversible: older restic versions can no longer read the repository.
+// Output lines are emitted as "migrate:progress".
+func (a *App) MigrateRepository(repoID, migration string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	available, err := a.GetMigrations(repoID)
+	if err != nil {
+		return err
+	}
+	if err := a.refuseInSafeMode(); err != nil {
+		return err
+	}
+	known := false
+	for _, m := range available {
+		known = known || m.Name == migration
+	}
+	if !known {
+		return fmt.Errorf("migration %q is not available for this repository", migration)
+	}
+	err = a.runner.RunWithProgress(resticRepo(repo), []string{"migrate", migration}, func(line string) {
+		runtime.EventsEmit(a.ctx, "migrate:progress", line)
+	})
+	// upgrade_repo_v2 ändert die Version in der Repository-Konfiguration
+	a.mu.Lock()
+	delete(a.formats, repo.URI)
+	a.mu.Unlock()
+	return err
+}
+
+// resticRepo maps a configured repository to the runner's settings
+func resticRepo(repo config.Repository) restic.Repo {
+	return restic.Repo{
+		URI:       repo.URI,
+		Password:  repo.Password,
+		ExtraArgs: repo.ExtraArgs,
+		Priority:  restic.Priority(repo.Priority),
+		CacheDir:  repo.CacheDir,
+		Env:       repo.Env,
+	}
+}
+
+// ── Profiles ──────────────────────────────────────────────────────
+
+// ListProfiles returns the names of all config profiles
+func (a *App) ListProfiles() []string {
+	return a.config.ListProfiles()
+}
+
+// GetActiveProfile returns the name of the loaded profile
+func (a *App) GetActiveProfile() string {
+	return a.config.Profile()
+}
+
+// SwitchProfile loads another set of repositories and settings, creating the
+// profile if needed. Running jobs keep using the repositories they started with.
+func (a *App) SwitchProfile(name string) error {
+	if err := a.config.SwitchProfile(name); err != nil {
+		return err
+	}
+	i18n.SetLanguage(a.config.GetLanguage())
+	a.jobs.SetLimit(a.config.GetMaxConcurrentJobs())
+	if r, ok := a.runner.(*restic.Runner); ok {
+		r.SetMaxOutput(a.config.MaxOutputBytes())
+	}
+	a.mu.Lock()
+	a.lsCache, a.snapLists, a.storage, a.repoStats = nil, nil, nil, nil
+	a.mu.Unlock()
+	runtime.EventsEmit(a.ctx, "config:reloaded", nil)
+	return nil
+}
+
+// ── Dateiauswahl ─────────────────────────────────────────────────
+
+func (a *App) SelectFolders() ([]string, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select source folder",
+	})
+	if err != nil || dir == "" {
+		return nil, err
+	}
+	return []string{dir}, nil
+}
+
+func (a *App) SelectRestoreFolder() (string, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select restore target folder",
+	})
+	return dir, err
+}
+
+func (a *App) SelectExportFile(defaultName string) (string, error) {
+	return runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export snapshot as archive",
+		DefaultFilename: defaultName,
+	})
+}
+
+// ── Backup API ────────────────────────────────────────────────────
+
+// StartBackup queues a backup and returns its job ID
+func (a *App) StartBackup(job restic.BackupJob) (string, error) {
+	return a.queueBackup(job, nil)
+}
+
+// queueBackup prüft job, baut die Argumente und reiht das Backup ein.
+// onDone (optional) erhält das Ergebnis, sobald der Job beendet ist; so
+// wartet --run-backup auf denselben Ablauf wie die GUI.
+func (a *App) queueBackup(job restic.BackupJob, onDone func(backupOutcome, error)) (string, error) {
+	if a.runner == nil {
+		return "", fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(job.RepoID)
+	if !ok {
+		return "", fmt.Errorf("repository not found")
+	}
+
+	if a.restoreOrCheckActive() {
+		return "", ErrBusy
+	}
+
+	a.touchRecentRepo(repo.ID)
+	plan, err := a.prepareBackup(repo, job)
+	if err != nil {
+		return "", err
+	}
+	// Erneut prüfen und unter derselben Sperre einreihen, die Restore und
+	// Check beim Markieren halten: dazwischen kann keiner von beiden starten
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.restoring) > 0 || a.checking {
+		return "", ErrBusy
+	}
+	// a.ops zählt den Job schon ab dem Einreihen, damit shutdown auch auf
+	// Backups wartet, die gerade erst starten
+	return a.jobs.SubmitTracked("backup", repo.ID, &a.ops, func(ctx context.Context, jobID string) error {
+		outcome, err := a.executeBackup(ctx, jobID, repo, plan)
+		if onDone != nil {
+			onDone(outcome, err)
+		}
+		return err
+	}), nil
+}
+
+// backupPlan enthält die fertigen Argumente eines Backups
+type backupPlan struct {
+	args     []string // vor den Einzel-Excludes
+	excludes []string // normalisiert, inkl. App-Verzeichnisse
+	rest     []string // Tags, Zeit, Parent und "--" mit den Quellpfaden
+	sources  []string
+}
+
+// prepareBackup checks job against the repository settings and builds the
+// restic arguments. StartBackup and --run-backup both use it, so sources,
+// tags, excludes and safe mode are handled the same with and without GUI.
+func (a *App) prepareBackup(repo config.Repository, job restic.BackupJob) (backupPlan, error) {
+	job.Tags = mergeUnique(repo.DefaultTags, job.Tags)
+
+	// Fehlende Quellen (gelöschter Ordner, nicht eingestecktes Laufwerk)
+	// vorher melden, sonst bricht restic mittendrin mit einer kryptischen Meldung ab
+	var sources []string
+	for _, src := range job.SourcePaths {
+		if _, err := os.Stat(src); err != nil {
+			if !job.SkipMissing {
+				return backupPlan{}, fmt.Errorf("source path not found: %s", src)
+			}
+			a.emit("backup:warning", "Skipping missing source path: "+src)
+			continue
+		}
+		sources = append(sources, src)
+	}
+	if len(sources) == 0 {
+		return backupPlan{}, fmt.Errorf("no source paths to back up")
+	}
+	if job.TagByFolder {
+		job.Tags = mergeUnique(job.Tags, folderTags(sources))
+	}
+
+	for _, w := range excludeWarnings(job.Excludes) {
+		a.emit("backup:warning", w)
+	}
+	excludes := restic.NormalizeExcludes(job.Excludes)
+
+	// restic-Cache und die eigene Konfiguration (enthält Passwörter) gehören nicht ins Backup
+	if !a.config.GetIncludeAppDirs() {
+		for _, dir := range appDirs(a.config) {
+			for _, src := range sources {
+				if pathContains(src, dir) {
+					a.emit("backup:warning",
+						fmt.Sprintf("%s contains %s, which is excluded from the backup", src, dir))
+				}
+			}
+			excludes = append(excludes, dir)
+		}
+	}
+
+	args := []string{"backup", "--json"}
+	if a.config.GetSafeMode() {
+		args = append(args, "--dry-run")
+	}
+	// Exclude-Dateien zuerst (z.B. gemeinsame Basis), dann die Einzel-Excludes
+	for _, f := range repo.ExcludeFiles {
+		if _, err := os.Stat(f); err != nil {
+			return backupPlan{}, fmt.Errorf("exclude file not found: %s", f)
+		}
+		args = append(args, "--exclude-file="+f)
+	}
+	// Die Einzel-Excludes kommen erst beim Start des Jobs dazu (ggf. als
+	// temporäre Datei, siehe patternArgs), damit ein abgebrochener Job in
+	// der Warteschlange keine Datei zurücklässt
+	var rest []string
+	for _, tag := range job.Tags {
+		rest = append(rest, "--tag="+tag)
+	}
+	if job.SnapshotTime != "" {
+		if _, err := time.Parse(restic.SnapshotTimeLayout, job.SnapshotTime); err != nil {
+			return backupPlan{}, fmt.Errorf("invalid snapshot time %q, expected YYYY-MM-DD HH:MM:SS", job.SnapshotTime)
+		}
+		rest = append(rest, "--time="+job.SnapshotTime)
+	}
+	if job.ParentSnapshot != "" {
+		rest = append(rest, "--parent="+job.ParentSnapshot)
+	}
+	// "--" beendet die Flags: Ordner wie "-foo" sind sonst für restic ein Flag
+	rest = append(rest, "--")
+	rest = append(rest, sources...)
+	return backupPlan{args: args, excludes: excludes, rest: rest, sources: sources}, nil
+}
+
+// executeBackup runs a prepared backup: size limit, pre-backup command,
+// restic backup with retries, post-backup command, history, events and
+// notifications
+func (a *App) executeBackup(ctx context.Context, jobID string, repo config.Repository, plan backupPlan) (backupOutcome, error) {
+	var outcome backupOutcome
+	if err := a.checkQuota(jobID, repo); err != nil {
+		a.emit("backup:error", err.Error())
+		a.notify("backup", repo.Name, err, "")
+		return outcome, err
+	}
+	err := a.runHook(ctx, jobID, "pre-backup", repo.PreBackupCommand, nil)
+	if err != nil && !repo.IgnorePreBackupErr {
+		err = fmt.Errorf("pre-backup command failed: %w", err)
+	} else if excludeArgs, cleanup, patErr := patternArgs("exclude", plan.excludes); patErr != nil {
+		err = patErr
+	} else {
+		fullArgs := append(append(append([]string{}, plan.args...), excludeArgs...), plan.rest...)
+		outcome, err = a.runBackup(ctx, jobID, repo, fullArgs, plan.sources)
+		cleanup()
+		a.dropSnapshotList(repo.ID)
+		if err == nil {
+			a.mu.Lock()
+			if a.lastAdded == nil {
+				a.lastAdded = map[string]uint64{}
+			}
+			a.lastAdded[repo.ID] = outcome.Summary.DataAdded
+			a.mu.Unlock()
+		}
+		status := "success"
+		if err != nil {
+			status = "failure"
+		}
+		if hookErr := a.runHook(ctx, jobID, "post-backup", repo.PostBackupCommand,
+			[]string{"RESTIC_GUI_BACKUP_STATUS=" + status}); hookErr != nil {
+			a.emit("backup:warning", "Post-backup command failed: "+hookErr.Error())
+		}
+	}
+	a.recordBackup(repo, outcome, err)
+	if err != nil {
+		a.emit("backup:error", err.Error())
+	} else {
+		a.emit("backup:complete", BackupComplete{
+			JobID:      jobID,
+			Summary:    outcome.Summary,
+			ErrorCount: outcome.ErrorCount,
+			FileErrors: outcome.FileErrors,
+		})
+	}
+	a.notify("backup", repo.Name, err, backupSummary(outcome.Summary))
+	return outcome, err
+}
+
+// maxFileErrors begrenzt die gemerkten Dateien mit Fehlern pro Backup
+const maxFileErrors = 200
+
+// backupOutcome sammelt, was ein Backup-Lauf gemeldet hat
+type backupOutcome struct {
+	Summary    restic.BackupProgress
+	ErrorCount int         // alle Dateifehler
+	FileErrors []FileError // die ersten maxFileErrors davon
+
+	lastJobProgress time.Time // zuletzt gesendetes "job:progress"
+}
+
+// addFileError zählt einen Dateifehler und merkt ihn bis maxFileErrors
+func (o *backupOutcome) addFileError(e FileError) {
+	o.ErrorCount++
+	if len(o.FileErrors) < maxFileErrors {
+		o.FileErrors = append(o.FileErrors, e)
+	}
+}
+
+// BackupComplete wird als "backup:complete" gesendet
+type BackupComplete struct {
+	JobID      string                `json:"jobId"`
+	Summary    restic.BackupProgress `json:"summary"`
+	ErrorCount int                   `json:"errorCount"`
+	FileErrors []FileError           `json:"fileErrors"` // höchstens maxFileErrors
+}
+
+// recordBackup schreibt einen Backup-Lauf in die Backup-Historie
+func (a *App) recordBackup(repo config.Repository, outcome backupOutcome, err error) {
+	if a.backups == nil {
+		return
+	}
+	entry := newBackupEntry(repo, outcome, err)
+	if histErr := a.backups.Add(entry); histErr != nil {
+		a.logWarning("Failed to record backup: " + histErr.Error())
+	}
+}
+
+// newBackupEntry fasst einen Backup-Lauf für die Historie zusammen
+func newBackupEntry(repo config.Repository, outcome backupOutcome, err error) history.BackupEntry {
+	entry := history.BackupEntry{
+		Time:       time.Now(),
+		RepoID:     repo.ID,
+		RepoName:   repo.Name,
+		SnapshotID: outcome.Summary.SnapshotID,
+		DryRun:     outcome.Summary.DryRun,
+		Success:    err == nil,
+		ErrorCount: outcome.ErrorCount,
+		FileErrors: outcome.FileErrors,
+
+		DurationSeconds: outcome.Summary.TotalDuration,
+		BytesProcessed:  outcome.Summary.TotalBytesProc,
+		BytesPerSecond:  outcome.Summary.BytesPerSecond,
+		DataAdded:       outcome.Summary.DataAdded,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// BackupMetric ist ein Punkt der Zeitreihe von GetBackupMetrics
+type BackupMetric struct {
+	Time            time.Time `json:"time"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	BytesProcessed  uint64    `json:"bytesProcessed"`
+	BytesPerSecond  float64   `json:"bytesPerSecond"`
+	DataAdded       uint64    `json:"dataAdded"`
+}
+
+// GetBackupMetrics returns duration and throughput of a repository's
+// recorded backups, oldest first, e.g. to chart whether backups get slower.
+// Failed runs and dry runs are left out since they would skew the series.
+func (a *App) GetBackupMetrics(repoID string) []BackupMetric {
+	metrics := []BackupMetric{}
+	if a.backups == nil {
+		return metrics
+	}
+	for _, e := range a.backups.List() {
+		if e.RepoID != repoID || !e.Success || e.DryRun || e.DurationSeconds <= 0 {
+			continue
+		}
+		metrics = append(metrics, BackupMetric{
+			Time:            e.Time,
+			DurationSeconds: e.DurationSeconds,
+			BytesProcessed:  e.BytesProcessed,
+			BytesPerSecond:  e.BytesPerSecond,
+			DataAdded:       e.DataAdded,
+		})
+	}
+	return metrics
+}
+
+// GetBackupHistory returns all recorded backups, newest first, including
+// the files restic could not read
+func (a *App) GetBackupHistory() []history.BackupEntry {
+	if a.backups == nil {
+		return []history.BackupEntry{}
+	}
+	entries := a.backups.List()
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
+// QuotaWarning wird als "backup:quotawarning" gesendet, wenn ein Backup das
+// MaxRepoSize eines Repositorys überschreiten würde
+type QuotaWarning struct {
+	JobID         string `json:"jobId"`
+	RepoID        string `json:"repoId"`
+	RepoName      string `json:"repoName"`
+	CurrentSize   uint64 `json:"currentSize"`
+	ProjectedSize uint64 `json:"projectedSize"` // aktuelle Größe + data_added des letzten Backups
+	MaxSize       uint64 `json:"maxSize"`
+	Refused       bool   `json:"refused"` // false = nur gewarnt (QuotaWarnOnly)
+}
+
+// ErrQuotaExceeded is returned when a backup would exceed MaxRepoSize
+var ErrQuotaExceeded = errors.New("repository size limit exceeded")
+
+// checkQuota vergleicht die aktuelle Repository-Größe plus den Zuwachs des
+// letzten Backups mit MaxRepoSize. Vor dem ersten Backup seit dem Start ist
+// der Zuwachs unbekannt, dann zählt nur die aktuelle Größe. Schlägt die
+// Abfrage fehl, läuft das Backup mit einer Warnung weiter.
+func (a *App) checkQuota(jobID string, repo config.Repository) error {
+	if repo.MaxRepoSize == 0 {
+		return nil
+	}
+	stats, err := a.GetRepositoryStats(repo.ID)
+	if err != nil {
+		a.emit("backup:warning", "Could not check the size limit: "+err.Error())
+		return nil
+	}
+	a.mu.Lock()
+	added := a.lastAdded[repo.ID]
+	a.mu.Unlock()
+
+	projected := stats.TotalSize + added
+	if projected <= repo.MaxRepoSize {
+		return nil
+	}
+	a.emit("backup:quotawarning", QuotaWarning{
+		JobID:         jobID,
+		RepoID:        repo.ID,
+		RepoName:      repo.Name,
+		CurrentSize:   stats.TotalSize,
+		ProjectedSize: projected,
+		MaxSize:       repo.MaxRepoSize,
+		Refused:       !repo.QuotaWarnOnly,
+	})
+	if repo.QuotaWarnOnly {
+		return nil
+	}
+	return ErrQuotaExceeded
+}
+
+// folderTags liefert den Ordnernamen jedes Quellpfads; Laufwerkswurzeln wie
+// "C:\" oder "/" haben keinen Namen und werden übersprungen. Kommas trennen
+// bei restic --tag mehrere Tags und werden daher ersetzt.
+func folderTags(sources []string) []string {
+	var tags []string
+	for _, src := range sources {
+		name := filepath.Base(filepath.Clean(src))
+		if name == "." || name == string(filepath.Separator) || strings.HasSuffix(name, ":") {
+			continue
+		}
+		tags = append(tags, strings.ReplaceAll(name, ",", "_"))
+	}
+	return tags
+}
+
+// appDirs lists the restic caches, including per-repository ones, and the
+// app's config directory
+func appDirs(cm *config.ConfigManager) []string {
+	var dirs []string
+	if dir := restic.CacheDir(); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	for _, repo := range cm.GetRepositories() {
+		if repo.CacheDir != "" {
+			dirs = append(dirs, repo.CacheDir)
+		}
+	}
+	return append(dirs, cm.Dir())
+}
+
+// pathContains reports whether child is parent or lies below it
+func pathContains(parent, child string) bool {
+	parent, child = filepath.Clean(parent), filepath.Clean(child)
+	if goruntime.GOOS == "windows" {
+		parent, child = strings.ToLower(parent), strings.ToLower(child)
+	}
+	rel, err := filepath.Rel(parent, child)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// GetExcludeAppDirs reports whether backups skip the restic cache and the
+// app's config directory
+func (a *App) GetExcludeAppDirs() bool {
+	return !a.config.GetIncludeAppDirs()
+}
+
+// SetExcludeAppDirs toggles the automatic excludes of StartBackup
+func (a *App) SetExcludeAppDirs(exclude bool) error {
+	return a.config.SetIncludeAppDirs(!exclude)
+}
+
+// BackupHook wird als "backup:hook" gesendet, wenn ein Pre-/Post-Befehl fertig ist
+type BackupHook struct {
+	JobID  string `json:"jobId"`
+	Stage  string `json:"stage"` // "pre-backup" oder "post-backup"
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+// runHook runs a repository's pre- or post-backup command and logs its output.
+// An empty command does nothing.
+func (a *App) runHook(ctx context.Context, jobID, stage, command string, env []string) error {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+	out, err := hooks.Run(ctx, command, env)
+	event := BackupHook{JobID: jobID, Stage: stage, Output: out}
+	if err != nil {
+		event.Error = err.Error()
+		a.logWarning(fmt.Sprintf("%s command failed: %v\n%s", stage, err, out))
+	} else {
+		a.logInfo(fmt.Sprintf("%s command finished\n%s", stage, out))
+	}
+	a.emit("backup:hook", event)
+	return err
+}
+
+// runBackup runs restic backup for a queued job, retrying after network errors.
+// restic backups are incremental, so a re-run after a network drop only
+// uploads what the interrupted run had not stored yet.
+func (a *App) runBackup(ctx context.Context, jobID string, repo config.Repository, args, sources []string) (backupOutcome, error) {
+	var err error
+	var outcome backupOutcome
+	for attempt := 0; ; attempt++ {
+		// Ein neuer Versuch liest alle Dateien erneut und meldet ihre Fehler noch einmal
+		outcome = backupOutcome{}
+		err = a.runner.RunWithProgressContext(ctx, resticRepo(repo), args, func(line string) {
+			a.handleBackupLine(jobID, line, sources, &outcome)
+		})
+		if restic.IsNetworkError(err) {
+			a.mu.Lock()
+			delete(a.reachable, repo.ID)
+			a.mu.Unlock()
+		}
+		if err == nil || attempt >= repo.BackupRetries || !restic.IsNetworkError(err) {
+			return outcome, err
+		}
+		delay := retryBaseDelay << attempt
+		a.emit("backup:retry", BackupRetry{
+			JobID:        jobID,
+			Attempt:      attempt + 1,
+			MaxRetries:   repo.BackupRetries,
+			DelaySeconds: int(delay.Seconds()),
+			Error:        err.Error(),
+		})
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return outcome, restic.ErrCancelled
+		}
+	}
+}
+
+// handleBackupLine sends one line of restic backup --json as the event
+// matching its message_type:
+//
+//	status         → "backup:progress" (reduced to BackupStatus, with the
+//	                 source paths the current files belong to)
+//	summary        → "backup:summary", also stored in outcome
+//	error          → "backup:fileerror" (FileError), also collected in outcome
+//	verbose_status → "backup:verbose" (restic.VerboseStatus)
+//
+// status and summary are additionally sent as "job:progress", status at
+// most once per jobProgressInterval.
+func (a *App) handleBackupLine(jobID, line string, sources []string, outcome *backupOutcome) {
+	var msg struct {
+		MessageType string `json:"message_type"`
+	}
+	if json.Unmarshal([]byte(line), &msg) != nil {
+		return
+	}
+	switch msg.MessageType {
+	case "status", "summary":
+		var progress restic.BackupProgress
+		if json.Unmarshal([]byte(line), &progress) != nil {
+			return
+		}
+		// Status-Meldungen kommen sehr häufig: nur die angezeigten Felder senden
+		status := progress.Status()
+		status.CurrentSources = sourceRoots(sources, progress.CurrentFiles)
+		var payload interface{} = status
+		event := "backup:progress"
+		if progress.MessageType == "summary" {
+			progress.AddThroughput()
+			outcome.Summary = progress
+			event, payload = "backup:summary", progress
+		}
+		a.emit(event, payload)
+		// die Jobliste braucht nicht jede Status-Zeile, die Zusammenfassung aber immer
+		if event == "backup:summary" || time.Since(outcome.lastJobProgress) >= jobProgressInterval {
+			outcome.lastJobProgress = time.Now()
+			a.emit("job:progress", JobProgress{JobID: jobID, Data: payload})
+		}
+	case "error":
+		var e restic.ErrorUpdate
+		if json.Unmarshal([]byte(line), &e) == nil {
+			fileErr := FileError{Path: e.Item, Message: e.Error.Message}
+			outcome.addFileError(fileErr)
+			a.emit("backup:fileerror", fileErr)
+		}
+	case "verbose_status":
+		var v restic.VerboseStatus
+		if json.Unmarshal([]byte(line), &v) == nil {
+			a.emit("backup:verbose", v)
+		}
+	}
+}
+
+// sourceRoots ordnet jede Datei dem längsten passenden Quellpfad zu und
+// liefert diese Quellpfade ohne Duplikate, in der Reihenfolge der Dateien
+func sourceRoots(sources, files []string) []string {
+	var roots []string
+	for _, file := range files {
+		best := ""
+		for _, src := range sources {
+			if len(src) > len(best) && pathContains(src, file) {
+				best = src
+			}
+		}
+		if best != "" && !slices.Contains(roots, best) {
+			roots = append(roots, best)
+		}
+	}
+	return roots
+}
+
+// backupSummary beschreibt eine summary-Meldung für Benachrichtigungen
+func backupSummary(s restic.BackupProgress) string {
+	if s.DryRun {
+		return fmt.Sprintf("Dry run (safe mode), nothing stored: %d new, %d changed files, %d bytes would be added",
+			s.FilesNew, s.FilesChanged, s.DataAdded)
+	}
+	if s.SnapshotID == "" {
+		return ""
+	}
+	return fmt.Sprintf("Snapshot %s: %d new, %d changed files, %d bytes added in %.0fs",
+		shortID(s.SnapshotID), s.FilesNew, s.FilesChanged, s.DataAdded, s.TotalDuration)
+}
+
+// CancelBackup cancels all queued and running backups
+func (a *App) CancelBackup() {
+	a.jobs.CancelKind("backup")
+}
+
+// ── Job Queue API ─────────────────────────────────────────────────
+
+// jobProgressInterval drosselt die "job:progress"-Events eines Backups
+const jobProgressInterval = time.Second
+
+// JobProgress wird als "job:progress" gesendet und ist per Job-ID zuordenbar
+type JobProgress struct {
+	JobID string      `json:"jobId"`
+	Data  interface{} `json:"data"`
+}
+
+// GetJobs returns running, queued and recently finished jobs
+func (a *App) GetJobs() []jobs.Info {
+	return a.jobs.List()
+}
+
+// CancelJob cancels a single queued or running job
+func (a *App) CancelJob(id string) error {
+	return a.jobs.Cancel(id)
+}
+
+// SetMaxConcurrentJobs sets how many backups may run at the same time
+func (a *App) SetMaxConcurrentJobs(n int) error {
+	if n < 1 {
+		return fmt.Errorf("at least one concurrent job is required")
+	}
+	a.jobs.SetLimit(n)
+	return a.config.SetMaxConcurrentJobs(n)
+}
+
+// ── Snapshot API ──────────────────────────────────────────────────
+
+func (a *App) GetSnapshots(repoID string) ([]restic.Snapshot, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	out, err := a.snapshotList(repo, false)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := restic.ParseSnapshots(out)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		snapshots[i].Note = repo.SnapshotNotes[snapshots[i].ID]
+	}
+	return snapshots, nil
+}
+
+// GetSnapshotBriefs lists snapshots with only id, time and host for a fast
+// first view of large repositories; GetSnapshot loads the details of one.
+// restic still reads every snapshot, but paths, tags and notes are neither
+// decoded nor sent to the UI.
+func (a *App) GetSnapshotBriefs(repoID string) ([]restic.SnapshotBrief, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	out, err := a.snapshotList(repo, true)
+	if err != nil {
+		return nil, err
+	}
+	return restic.ParseSnapshotBriefs(out)
+}
+
+// GetSnapshot returns the full details of one snapshot, including its note
+func (a *App) GetSnapshot(repoID, snapshotID string) (restic.Snapshot, error) {
+	if a.runner == nil {
+		return restic.Snapshot{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return restic.Snapshot{}, fmt.Errorf("repository not found")
+	}
+	snap, err := a.getSnapshot(repo, snapshotID)
+	if err != nil {
+		return restic.Snapshot{}, err
+	}
+	snap.Note = repo.SnapshotNotes[snap.ID]
+	return snap, nil
+}
+
+// SetSnapshotNote attaches a free-text note to a snapshot. Notes are stored
+// in the app's config since restic has no such field; an empty note removes it.
+func (a *App) SetSnapshotNote(repoID, snapshotID, note string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	// Kurz-IDs auflösen, damit die Notiz beim vollständigen Schlüssel landet
+	snap, err := a.getSnapshot(repo, snapshotID)
+	if err != nil {
+		return err
+	}
+	return a.config.SetSnapshotNote(repo.ID, snap.ID, strings.TrimSpace(note))
+}
+
+// PinBaselineSnapshot remembers a snapshot as the default "from" side of
+// DiffSnapshots, e.g. the last known-good state; an empty ID removes the pin
+func (a *App) PinBaselineSnapshot(repoID, snapshotID string) error {
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	if snapshotID == "" {
+		return a.config.SetBaselineSnapshot(repo.ID, "")
+	}
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	snap, err := a.getSnapshot(repo, snapshotID)
+	if err != nil {
+		return err
+	}
+	return a.config.SetBaselineSnapshot(repo.ID, snap.ID)
+}
+
+// DiffSnapshots lists the changes between two snapshots using restic diff.
+// An empty fromID compares against the pinned baseline snapshot.
+func (a *App) DiffSnapshots(repoID, fromID, toID string) (restic.DiffResult, error) {
+	if a.runner == nil {
+		return restic.DiffResult{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return restic.DiffResult{}, fmt.Errorf("repository not found")
+	}
+	if fromID == "" {
+		fromID = repo.BaselineSnapshot
+		if fromID == "" {
+			return restic.DiffResult{}, fmt.Errorf("no baseline snapshot pinned for this repository")
+		}
+	}
+	if toID == "" {
+		return restic.DiffResult{}, fmt.Errorf("no snapshot to compare selected")
+	}
+	out, err := a.runner.Run(resticRepo(repo), []string{"diff", "--json", "--", fromID, toID})
+	if err != nil {
+		return restic.DiffResult{}, err
+	}
+	return restic.ParseDiff(out)
+}
+
+// FindSnapshotByDate returns the snapshot that was current at the given
+// time: the newest one taken at or before it. A non-empty path restricts the
+// search to snapshots of that path (restic's --path selector).
+func (a *App) FindSnapshotByDate(repoID string, at time.Time, path string) (restic.Snapshot, error) {
+	if a.runner == nil {
+		return restic.Snapshot{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return restic.Snapshot{}, fmt.Errorf("repository not found")
+	}
+	var out string
+	var err error
+	if path == "" {
+		out, err = a.snapshotList(repo, true)
+	} else {
+		out, err = a.runner.Run(resticRepo(repo), []string{"snapshots", "--json", "--path=" + path})
+	}
+	if err != nil {
+		return restic.Snapshot{}, err
+	}
+	snapshots, err := restic.ParseSnapshots(out)
+	if err != nil {
+		return restic.Snapshot{}, err
+	}
+	var found *restic.Snapshot
+	var foundTime time.Time
+	for i := range snapshots {
+		t := snapshotTime(snapshots[i])
+		if t.IsZero() || t.After(at) {
+			continue
+		}
+		if found == nil || t.After(foundTime) {
+			found, foundTime = &snapshots[i], t
+		}
+	}
+	if found == nil {
+		return restic.Snapshot{}, fmt.Errorf("no snapshot found at or before %s", at.Local().Format("2006-01-02 15:04"))
+	}
+	found.Note = repo.SnapshotNotes[found.ID]
+	return *found, nil
+}
+
+// calendarDayLayout: Schlüssel von GetSnapshotCalendar
+const calendarDayLayout = "2006-01-02"
+
+// GetSnapshotCalendar counts the snapshots taken per day between from and
+// to (inclusive), e.g. for an activity heatmap. Days are keys like
+// "2026-10-15" in the computer's local time zone, the same zone the UI
+// shows snapshot times in; days without snapshots are left out.
+func (a *App) GetSnapshotCalendar(repoID string, from, to time.Time) (map[string]int, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("end of range is before its start")
+	}
+	out, err := a.snapshotList(repo, true)
+	if err != nil {
+		return nil, err
+	}
+	briefs, err := restic.ParseSnapshotBriefs(out)
+	if err != nil {
+		return nil, err
+	}
+	days := map[string]int{}
+	for _, b := range briefs {
+		t, err := time.Parse(time.RFC3339Nano, b.Time)
+		if err != nil || t.Before(from) || t.After(to) {
+			continue
+		}
+		days[t.Local().Format(calendarDayLayout)]++
+	}
+	return days, nil
+}
+
+// GetSnapshotGroups lists snapshots grouped by restic itself, e.g. with
+// groupBy "host,tags,paths". An empty groupBy returns one group with all snapshots.
+func (a *App) GetSnapshotGroups(repoID, groupBy string) ([]restic.SnapshotGroup, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	args := []string{"snapshots", "--json"}
+	if groupBy != "" {
+		if err := restic.ValidateGroupBy(groupBy); err != nil {
+			return nil, err
+		}
+		args = append(args, "--group-by", groupBy)
+	}
+	out, err := a.runner.Run(resticRepo(repo), args)
+	if err != nil {
+		return nil, err
+	}
+	return restic.ParseSnapshotGroups(out)
+}
+
+// EnrichSnapshots fetches the restore size of every snapshot in the
+// background and emits "snapshot:enriched" with restic.SnapshotStats as each
+// one completes, followed by "snapshot:enrichdone". A running enrichment is
+// cancelled when a new one starts or via CancelSnapshotEnrichment.
+func (a *App) EnrichSnapshots(repoID string, snapshotIDs []string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.mu.Lock()
+	if a.enrichCancel != nil {
+		a.enrichCancel()
+	}
+	a.enrichCancel = cancel
+	a.mu.Unlock()
+
+	a.goOp(func() {
+		defer cancel()
+		for _, id := range snapshotIDs {
+			out, err := a.runner.RunContext(ctx, resticRepo(repo), []string{"stats", "--json", "--", id})
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				continue
+			}
+			var stats restic.SnapshotStats
+			if err := json.Unmarshal([]byte(out), &stats); err != nil {
+				continue
+			}
+			stats.SnapshotID = id
+			runtime.EventsEmit(a.ctx, "snapshot:enriched", stats)
+		}
+		runtime.EventsEmit(a.ctx, "snapshot:enrichdone", repoID)
+	})
+	return nil
+}
+
+// CancelSnapshotEnrichment stops a running EnrichSnapshots
+func (a *App) CancelSnapshotEnrichment() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.enrichCancel != nil {
+		a.enrichCancel()
+		a.enrichCancel = nil
+	}
+}
+
+// DeletePreview describes what deleting a snapshot would remove. Token
+// confirms the deletion in DeleteSnapshot and expires after deleteTokenTTL.
+type DeletePreview struct {
+	Snapshot  restic.Snapshot `json:"snapshot"`
+	RawSize   uint64          `json:"rawSize"`   // Obergrenze dessen, was prune freigibt; geteilte Daten bleiben
+	Remaining int             `json:"remaining"` // übrige Snapshots im Repository
+	Token     string          `json:"token"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+}
+
+// pendingDelete ist ein per PreviewDeleteSnapshot bestätigbarer Löschauftrag
+type pendingDelete struct {
+	repoID     string
+	snapshotID string
+	expires    time.Time
+}
+
+// deleteTokenTTL begrenzt, wie lange eine Vorschau zum Löschen berechtigt
+const deleteTokenTTL = 5 * time.Minute
+
+// PreviewDeleteSnapshot shows what DeleteSnapshot would remove and returns
+// the confirmation token it requires
+func (a *App) PreviewDeleteSnapshot(repoID, snapshotID string) (DeletePreview, error) {
+	if a.runner == nil {
+		return DeletePreview{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return DeletePreview{}, fmt.Errorf("repository not found")
+	}
+	snap, err := a.getSnapshot(repo, snapshotID)
+	if err != nil {
+		return DeletePreview{}, err
+	}
+	if snap.IsProtected() {
+		return DeletePreview{}, fmt.Errorf("snapshot %s is protected; unprotect it first", snap.ShortID)
+	}
+
+	preview := DeletePreview{Snapshot: snap}
+	out, err := a.runner.Run(resticRepo(repo), []string{"stats", "--json", "--mode", "raw-data", "--", snap.ID})
+	if err != nil {
+		return DeletePreview{}, err
+	}
+	var stats restic.RepoStats
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		return DeletePreview{}, fmt.Errorf("failed to parse repository stats")
+	}
+	preview.RawSize = stats.TotalSize
+	snapshots, err := a.GetSnapshots(repoID)
+	if err != nil {
+		return DeletePreview{}, err
+	}
+	preview.Remaining = len(snapshots) - 1
+
+	preview.Token = uuid.New().String()
+	preview.ExpiresAt = time.Now().Add(deleteTokenTTL)
+	a.mu.Lock()
+	if a.deleteTokens == nil {
+		a.deleteTokens = map[string]pendingDelete{}
+	}
+	for token, p := range a.deleteTokens {
+		if time.Now().After(p.expires) {
+			delete(a.deleteTokens, token)
+		}
+	}
+	a.deleteTokens[preview.Token] = pendingDelete{repoID: repo.ID, snapshotID: snap.ID, expires: preview.ExpiresAt}
+	a.mu.Unlock()
+	return preview, nil
+}
+
+// DeleteSnapshot forgets a snapshot and prunes its data. token must come
+// from PreviewDeleteSnapshot for the same snapshot and can be used once.
+// The two phases are reported as "forget:progress" and "prune:progress".
+func (a *App) DeleteSnapshot(repoID, snapshotID, token string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	if err := a.refuseInSafeMode(); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	pending, ok := a.deleteTokens[token]
+	delete(a.deleteTokens, token)
+	a.mu.Unlock()
+	if !ok || time.Now().After(pending.expires) {
+		return fmt.Errorf("deletion not confirmed; preview it again")
+	}
+	if pending.repoID != repo.ID || !strings.HasPrefix(pending.snapshotID, snapshotID) {
+		return fmt.Errorf("confirmation belongs to a different snapshot")
+	}
+
+	// Der Schutz kann sich seit der Vorschau geändert haben
+	snap, err := a.getSnapshot(repo, pending.snapshotID)
+	if err != nil {
+		return err
+	}
+	if snap.IsProtected() {
+		return fmt.Errorf("snapshot %s is protected; unprotect it first", snap.ShortID)
+	}
+	// forget und prune getrennt, damit die UI sieht, welche Phase läuft
+	runtime.EventsEmit(a.ctx, "forget:progress", restic.PhaseProgress{Phase: "forget", Percent: -1, Message: "removing snapshot " + snap.ShortID})
+	err = a.runner.RunWithProgress(resticRepo(repo), []string{"forget", "--", snap.ID}, func(line string) {
+		if line = strings.TrimSpace(line); line != "" {
+			runtime.EventsEmit(a.ctx, "forget:progress", restic.PhaseProgress{Phase: "forget", Percent: -1, Message: line})
+		}
+	})
+	a.dropSnapshotList(repo.ID)
+	if err != nil {
+		return err
+	}
+	runtime.EventsEmit(a.ctx, "forget:progress", restic.PhaseProgress{Phase: "forget", Percent: 100, Message: "snapshot removed"})
+	if repo.BaselineSnapshot == snap.ID {
+		if err := a.config.SetBaselineSnapshot(repo.ID, ""); err != nil {
+			return err
+		}
+	}
+	if repo.SnapshotNotes[snap.ID] != "" {
+		if err := a.config.SetSnapshotNote(repo.ID, snap.ID, ""); err != nil {
+			return err
+		}
+	}
+	return a.prune(repo)
+}
+
+// tagColorPattern: Farben als #rrggbb, damit die UI sie direkt verwenden kann
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// GetTagColors returns the color assigned to each snapshot tag
+func (a *App) GetTagColors() map[string]string {
+	return a.config.GetTagColors()
+}
+
+// SetTagColor assigns a color ("#rrggbb") to a snapshot tag, replacing an
+// earlier one. An empty color removes the assignment.
+func (a *App) SetTagColor(tag, color string) error {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return fmt.Errorf("no tag given")
+	}
+	if color != "" && !tagColorPattern.MatchString(color) {
+		return fmt.Errorf("invalid color %q, expected #rrggbb", color)
+	}
+	return a.config.SetTagColor(tag, strings.ToLower(color))
+}
+
+// getSnapshot fetches a single snapshot by (short) ID
+func (a *App) getSnapshot(repo config.Repository, snapshotID string) (restic.Snapshot, error) {
+	out, err := a.runner.Run(resticRepo(repo), []string{"snapshots", "--json", "--", snapshotID})
+	if err != nil {
+		return restic.Snapshot{}, err
+	}
+	snaps, err := restic.ParseSnapshots(out)
+	if err != nil {
+		return restic.Snapshot{}, err
+	}
+	if len(snaps) == 0 {
+		return restic.Snapshot{}, fmt.Errorf("snapshot not found")
+	}
+	return snaps[0], nil
+}
+
+// ProtectSnapshot tags a snapshot so DeleteSnapshot and ApplyRetention
+// never remove it. restic rewrites tagged snapshots, so the snapshot gets
+// a new ID; its note and a baseline pin are moved to that ID.
+func (a *App) ProtectSnapshot(repoID, snapshotID string) error {
+	return a.setSnapshotTag(repoID, snapshotID, "--add")
+}
+
+// UnprotectSnapshot removes the protection added by ProtectSnapshot
+func (a *App) UnprotectSnapshot(repoID, snapshotID string) error {
+	return a.setSnapshotTag(repoID, snapshotID, "--remove")
+}
+
+func (a *App) setSnapshotTag(repoID, snapshotID, mode string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	// restic tag schreibt den Snapshot unter neuer ID neu
+	if err := a.refuseInSafeMode(); err != nil {
+		return err
+	}
+	snap, err := a.getSnapshot(repo, snapshotID)
+	if err != nil {
+		return err
+	}
+	// Schon im gewünschten Zustand: restic würde nichts umschreiben
+	if snap.IsProtected() == (mode == "--add") {
+		return nil
+	}
+	_, err = a.runner.Run(resticRepo(repo), []string{"tag", mode, restic.ProtectedTag, "--", snap.ID})
+	a.dropSnapshotList(repo.ID)
+	if err != nil {
+		return err
+	}
+	// Notiz und Vergleichsbasis hängen an der Snapshot-ID und müssen zur
+	// neuen ID mitwandern
+	note := repo.SnapshotNotes[snap.ID]
+	pinned := repo.BaselineSnapshot == snap.ID
+	if note == "" && !pinned {
+		return nil
+	}
+	rewritten, err := a.rewrittenSnapshot(repo, snap)
+	if err != nil {
+		return err
+	}
+	if pinned {
+		if err := a.config.SetBaselineSnapshot(repo.ID, rewritten.ID); err != nil {
+			return err
+		}
+	}
+	if note == "" {
+		return nil
+	}
+	if err := a.config.SetSnapshotNote(repo.ID, rewritten.ID, note); err != nil {
+		return err
+	}
+	return a.config.SetSnapshotNote(repo.ID, snap.ID, "")
+}
+
+// rewrittenSnapshot sucht den Snapshot, den restic tag aus old geschrieben
+// hat: restic trägt dort old bzw. dessen Original als "original" ein und
+// übernimmt den Zeitpunkt
+func (a *App) rewrittenSnapshot(repo config.Repository, old restic.Snapshot) (restic.Snapshot, error) {
+	origin := old.ID
+	if old.Original != "" {
+		origin = old.Original
+	}
+	out, err := a.snapshotList(repo, false)
+	if err != nil {
+		return restic.Snapshot{}, err
+	}
+	snapshots, err := restic.ParseSnapshots(out)
+	if err != nil {
+		return restic.Snapshot{}, err
+	}
+	for _, s := range snapshots {
+		if s.ID != old.ID && s.Original == origin && s.Time == old.Time {
+			return s, nil
+		}
+	}
+	return restic.Snapshot{}, fmt.Errorf("rewritten snapshot of %s not found", old.ShortID)
+}
+
+// snapshotList liefert die Ausgabe von "restic snapshots --json". Mit reuse
+// wird eine höchstens snapListMaxAge alte Liste wiederverwendet, damit
+// aufeinanderfolgende Lesezugriffe beim Öffnen eines Repositories (Liste,
+// Kalender, ...) nur einen restic-Prozess starten. Ohne reuse wird immer neu
+// gelesen und die Liste für Folgeabfragen gespeichert.
+func (a *App) snapshotList(repo config.Repository, reuse bool) (string, error) {
+	if reuse {
+		a.mu.Lock()
+		entry, ok := a.snapLists[repo.ID]
+		a.mu.Unlock()
+		if ok && entry.uri == repo.URI && time.Since(entry.at) <= snapListMaxAge {
+			return entry.out, nil
+		}
+	}
+	out, err := a.runner.Run(resticRepo(repo), []string{"snapshots", "--json"})
+	if err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	if a.snapLists == nil {
+		a.snapLists = map[string]snapListEntry{}
+	}
+	a.snapLists[repo.ID] = snapListEntry{uri: repo.URI, out: out, at: time.Now()}
+	a.mu.Unlock()
+	return out, nil
+}
+
+// dropSnapshotList verwirft die gespeicherte Liste nach Änderungen an den
+// Snapshots (Backup, forget, tag)
+func (a *App) dropSnapshotList(repoID string) {
+	a.mu.Lock()
+	delete(a.snapLists, repoID)
+	a.mu.Unlock()
+}
+
+// ── Storage API ───────────────────────────────────────────────────
+
+// RepoStorage ist die Belegung eines Repositories in GetTotalStorageUsage
+type RepoStorage struct {
+	RepoID   string           `json:"repoId"`
+	RepoName string           `json:"repoName"`
+	Stats    restic.RepoStats `json:"stats"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// StorageUsage sums the raw data size of all repositories
+type StorageUsage struct {
+	Repos     []RepoStorage `json:"repos"`
+	TotalSize uint64        `json:"totalSize"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// GetRepositoryStats returns the storage actually used by a repository
+func (a *App) GetRepositoryStats(repoID string) (restic.RepoStats, error) {
+	if a.runner == nil {
+		return restic.RepoStats{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return restic.RepoStats{}, fmt.Errorf("repository not found")
+	}
+	out, err := a.runner.Run(resticRepo(repo), []string{"stats", "--json", "--mode", "raw-data"})
+	if err != nil {
+		return restic.RepoStats{}, err
+	}
+	var stats restic.RepoStats
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		return restic.RepoStats{}, fmt.Errorf("failed to parse repository stats")
+	}
+	return stats, nil
+}
+
+// Reclaimable ist das zwischengespeicherte Ergebnis von GetReclaimableSpace
+type Reclaimable struct {
+	restic.PruneEstimate
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// GetReclaimableSpace reports how much unreferenced data a prune would
+// remove, using "prune --dry-run" (nothing is modified). The result is
+// cached per repository until the next prune; refresh forces a new run.
+func (a *App) GetReclaimableSpace(repoID string, refresh bool) (Reclaimable, error) {
+	if a.runner == nil {
+		return Reclaimable{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return Reclaimable{}, fmt.Errorf("repository not found")
+	}
+	a.mu.Lock()
+	cached, ok := a.reclaimable[repo.ID]
+	a.mu.Unlock()
+	if ok && !refresh {
+		return cached, nil
+	}
+	out, err := a.runner.Run(resticRepo(repo), []string{"prune", "--dry-run"})
+	if err != nil {
+		return Reclaimable{}, err
+	}
+	res := Reclaimable{PruneEstimate: restic.ParsePruneDryRun(out), UpdatedAt: time.Now()}
+	a.mu.Lock()
+	if a.reclaimable == nil {
+		a.reclaimable = map[string]Reclaimable{}
+	}
+	a.reclaimable[repo.ID] = res
+	a.mu.Unlock()
+	return res, nil
+}
+
+// prune führt "restic prune" aus, sendet den Fortschritt als "prune:progress"
+// und verwirft die Schätzung von GetReclaimableSpace
+func (a *App) prune(repo config.Repository) error {
+	err := a.runner.RunWithProgress(resticRepo(repo).WithProgress().WithStderrLines(), []string{"prune"}, func(line string) {
+		if p, ok := restic.ParsePruneLine(line); ok {
+			runtime.EventsEmit(a.ctx, "prune:progress", p)
+		}
+	})
+	a.mu.Lock()
+	delete(a.reclaimable, repo.ID)
+	a.mu.Unlock()
+	return err
+}
+
+// GetTotalStorageUsage returns the storage used by all repositories. The
+// result is cached; refresh=true queries every repository again (concurrently).
+// Failing repositories are reported per entry and don't count towards the total.
+func (a *App) GetTotalStorageUsage(refresh bool) (StorageUsage, error) {
+	if a.runner == nil {
+		return StorageUsage{}, fmt.Errorf("restic not found")
+	}
+	a.mu.Lock()
+	cached := a.storage
+	a.mu.Unlock()
+	if cached != nil && !refresh {
+		return *cached, nil
+	}
+
+	repos := a.config.GetRepositories()
+	usage := StorageUsage{Repos: make([]RepoStorage, len(repos))}
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo config.Repository) {
+			defer wg.Done()
+			res := RepoStorage{RepoID: repo.ID, RepoName: repo.Name}
+			stats, err := a.GetRepositoryStats(repo.ID)
+			if err != nil {
+				res.Error = err.Error()
+			}
+			res.Stats = stats
+			usage.Repos[i] = res
+		}(i, repo)
+	}
+	wg.Wait()
+
+	for _, r := range usage.Repos {
+		usage.TotalSize += r.Stats.TotalSize
+	}
+	usage.UpdatedAt = time.Now()
+	a.mu.Lock()
+	a.storage = &usage
+	a.mu.Unlock()
+	return usage, nil
+}
+
+// RepoStatus wird als "repo:statsupdated" gesendet und von GetRepoStatuses geliefert
+type RepoStatus struct {
+	RepoID     string           `json:"repoId"`
+	RepoName   string           `json:"repoName"`
+	Stats      restic.RepoStats `json:"stats"`
+	LastBackup string           `json:"lastBackup"` // Zeit des neuesten Snapshots, leer = keiner
+	Error      string           `json:"error,omitempty"`
+	UpdatedAt  time.Time        `json:"updatedAt"`
+}
+
+// pollIdleCheck: so oft prüft ein abgeschalteter Poller, ob er wieder aktiv ist
+const pollIdleCheck = time.Minute
+
+// pollStats refreshes size and last backup of every repository in the
+// interval configured via SetStatsPolling until stop is closed
+func (a *App) pollStats(stop <-chan struct{}) {
+	for {
+		minutes, onMetered := a.config.GetStatsPolling()
+		wait := time.Duration(minutes) * time.Minute
+		if minutes <= 0 {
+			wait = pollIdleCheck
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+		if minutes <= 0 || a.busy() || (!onMetered && netcost.Metered()) {
+			continue
+		}
+		for _, repo := range a.config.GetRepositories() {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			status := a.repoStatus(repo)
+			a.mu.Lock()
+			if a.repoStats == nil {
+				a.repoStats = map[string]RepoStatus{}
+			}
+			a.repoStats[repo.ID] = status
+			a.mu.Unlock()
+			runtime.EventsEmit(a.ctx, "repo:statsupdated", status)
+		}
+	}
+}
+
+// repoStatus fragt Größe und neuesten Snapshot eines Repositories ab
+func (a *App) repoStatus(repo config.Repository) RepoStatus {
+	status := RepoStatus{RepoID: repo.ID, RepoName: repo.Name, UpdatedAt: time.Now()}
+	stats, err := a.GetRepositoryStats(repo.ID)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Stats = stats
+	snapshots, err := a.GetSnapshots(repo.ID)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	var latest time.Time
+	for _, s := range snapshots {
+		if t := snapshotTime(s); t.After(latest) {
+			latest, status.LastBackup = t, s.Time
+		}
+	}
+	return status
+}
+
+// GetRepoStatuses returns the cached results of the background poller
+func (a *App) GetRepoStatuses() []RepoStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	statuses := []RepoStatus{}
+	for _, repo := range a.config.GetRepositories() {
+		if s, ok := a.repoStats[repo.ID]; ok {
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses
+}
+
+// GetStatsPolling returns the poll interval in minutes (0 = off) and
+// whether polling continues on metered connections
+func (a *App) GetStatsPolling() map[string]interface{} {
+	minutes, onMetered := a.config.GetStatsPolling()
+	return map[string]interface{}{"minutes": minutes, "pollOnMetered": onMetered}
+}
+
+// SetStatsPolling configures the background poller; 0 minutes turns it off
+func (a *App) SetStatsPolling(minutes int, pollOnMetered bool) error {
+	if minutes < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+	return a.config.SetStatsPolling(minutes, pollOnMetered)
+}
+
+// ── Notifications ─────────────────────────────────────────────────
+
+// GetNotificationTargets returns the configured e-mail and webhook targets
+func (a *App) GetNotificationTargets() []notify.Target {
+	return a.config.GetNotifications()
+}
+
+// SetNotificationTargets validates and stores the notification targets
+func (a *App) SetNotificationTargets(targets []notify.Target) error {
+	for _, t := range targets {
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", t.Name, err)
+		}
+	}
+	return a.config.SetNotifications(targets)
+}
+
+// TestNotification sends a sample event to a single target
+func (a *App) TestNotification(target notify.Target) error {
+	if err := target.Validate(); err != nil {
+		return err
+	}
+	return notify.Send(a.ctx, target, notify.Event{
+		Kind: "test", Repo: "Restic Backup Manager", Status: "success",
+		Summary: "This is a test notification.", Time: time.Now(),
+	})
+}
+
+// notify sendet das Ergebnis eines Jobs im Hintergrund an alle Ziele
+func (a *App) notify(kind, repoName string, err error, summary string) {
+	targets := a.config.GetNotifications()
+	if len(targets) == 0 {
+		return
+	}
+	event := notify.Event{Kind: kind, Repo: repoName, Status: "success", Summary: summary, Time: time.Now()}
+	if err != nil {
+		event.Status = "failure"
+		event.Summary = strings.TrimSpace(err.Error() + "\n" + summary)
+	}
+	for _, t := range targets {
+		if t.OnlyFailures && !event.Failed() {
+			continue
+		}
+		t := t
+		a.goOp(func() {
+			if sendErr := notify.Send(a.ctx, t, event); sendErr != nil {
+				a.logWarning(fmt.Sprintf("Notification %q failed: %v", t.Name, sendErr))
+			}
+		})
+	}
+}
+
+// ── Search API ────────────────────────────────────────────────────
+
+// RepoFindResult bündelt die Treffer einer Suche pro Repository
+type RepoFindResult struct {
+	RepoID   string              `json:"repoId"`
+	RepoName string              `json:"repoName"`
+	Results  []restic.FindResult `json:"results"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// FindInSnapshots searches all snapshots of a repository via restic find --json
+func (a *App) FindInSnapshots(repoID, pattern string) ([]restic.FindResult, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("no search pattern given")
+	}
+	// "--": ein Muster wie "-foo*" darf nicht als Flag gelesen werden
+	out, err := a.runner.Run(resticRepo(repo), []string{"find", "--json", "--", pattern})
+	if err != nil {
+		return nil, err
+	}
+	var results []restic.FindResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		return nil, fmt.Errorf("failed to parse search results")
+	}
+	return results, nil
+}
+
+// FindEverywhere searches every configured repository concurrently.
+// Failing repositories are reported per entry instead of aborting the search.
+func (a *App) FindEverywhere(pattern string) ([]RepoFindResult, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repos := a.config.GetRepositories()
+	results := make([]RepoFindResult, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo config.Repository) {
+			defer wg.Done()
+			res := RepoFindResult{RepoID: repo.ID, RepoName: repo.Name}
+			found, err := a.FindInSnapshots(repo.ID, pattern)
+			if err != nil {
+				res.Error = err.Error()
+			}
+			res.Results = found
+			results[i] = res
+		}(i, repo)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// ApplyRetention forgets snapshots according to policy and prunes the
+// unreferenced data. With dryRun=true nothing is changed and the returned
+// groups show which snapshots would be kept or removed and why. Safe mode
+// turns every call into a dry run.
+func (a *App) ApplyRetention(repoID string, policy restic.ForgetPolicy, dryRun bool) ([]restic.ForgetGroup, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	return a.forget(repo, policy, nil, dryRun)
+}
+
+// ForgetByTag applies policy only to the snapshots carrying tag, e.g.
+// keeping the last three snapshots tagged "scratch"; all other snapshots
+// stay untouched. dryRun works as in ApplyRetention.
+func (a *App) ForgetByTag(repoID, tag string, policy restic.ForgetPolicy, dryRun bool) ([]restic.ForgetGroup, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return nil, fmt.Errorf("no tag given")
+	}
+	// Ein Komma verknüpft bei restic mehrere Tags mit UND
+	if strings.Contains(tag, ",") {
+		return nil, fmt.Errorf("tag must not contain a comma: %s", tag)
+	}
+	return a.forget(repo, policy, []string{"--tag", tag}, dryRun)
+}
+
+// forget führt restic forget mit policy aus; filter schränkt die
+// betrachteten Snapshots ein (z.B. --tag). Ohne dryRun wird danach gepruned.
+func (a *App) forget(repo config.Repository, policy restic.ForgetPolicy, filter []string, dryRun bool) ([]restic.ForgetGroup, error) {
+	if policy.IsEmpty() {
+		return nil, fmt.Errorf("retention policy is empty")
+	}
+	// Im Safe-Mode nur die Vorschau: forget --dry-run, kein prune
+	if a.config.GetSafeMode() {
+		dryRun = true
+	}
+	policyArgs, err := policy.Args()
+	if err != nil {
+		return nil, err
+	}
+
+	args := append(append([]string{"forget", "--json"}, filter...), policyArgs...)
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	out, err := a.runner.Run(resticRepo(repo), args)
+	if !dryRun {
+		a.dropSnapshotList(repo.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var groups []restic.ForgetGroup
+	if err := json.Unmarshal([]byte(out), &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse forget output")
+	}
+	if dryRun {
+		return groups, nil
+	}
+
+	var removed []restic.Snapshot
+	for _, g := range groups {
+		removed = append(removed, g.Remove...)
+	}
+	a.dropSnapshotRefs(repo, removed)
+	if len(removed) > 0 {
+		if err := a.prune(repo); err != nil {
+			return groups, err
+		}
+	}
+	return groups, nil
+}
+
+// ForgetRange removes all snapshots taken between from and to (inclusive),
+// optionally only those of one host, and prunes their data. Protected
+// snapshots are kept. With dryRun=true nothing is changed and the returned
+// list shows which snapshots would be removed. Safe mode turns every call
+// into a dry run.
+func (a *App) ForgetRange(repoID, host string, from, to time.Time, dryRun bool) ([]restic.Snapshot, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("end of range is before its start")
+	}
+	if a.config.GetSafeMode() {
+		dryRun = true
+	}
+
+	args := []string{"snapshots", "--json"}
+	if host != "" {
+		args = append(args, "--host="+host)
+	}
+	out, err := a.runner.Run(resticRepo(repo), args)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := restic.ParseSnapshots(out)
+	if err != nil {
+		return nil, err
+	}
+	matched := []restic.Snapshot{}
+	for _, snap := range snapshots {
+		t := snapshotTime(snap)
+		if t.IsZero() || t.Before(from) || t.After(to) || snap.IsProtected() {
+			continue
+		}
+		matched = append(matched, snap)
+	}
+	if dryRun || len(matched) == 0 {
+		return matched, nil
+	}
+
+	// Alle IDs in einem forget-Aufruf, solange die Kommandozeile nicht zu lang wird
+	var batch []string
+	batchLen := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := a.runner.Run(resticRepo(repo), append([]string{"forget", "--"}, batch...))
+		a.dropSnapshotList(repo.ID)
+		batch, batchLen = nil, 0
+		return err
+	}
+	for _, snap := range matched {
+		if batchLen+len(snap.ID)+1 > maxInlineArgs {
+			if err := flush(); err != nil {
+				return matched, err
+			}
+		}
+		batch = append(batch, snap.ID)
+		batchLen += len(snap.ID) + 1
+	}
+	if err := flush(); err != nil {
+		return matched, err
+	}
+	a.dropSnapshotRefs(repo, matched)
+	if err := a.prune(repo); err != nil {
+		return matched, err
+	}
+	return matched, nil
+}
+
+// dropSnapshotRefs entfernt Vergleichsbasis und Notizen entfernter
+// Snapshots aus der Konfiguration; Fehler werden nur protokolliert, die
+// Snapshots sind dann ja schon weg
+func (a *App) dropSnapshotRefs(repo config.Repository, removed []restic.Snapshot) {
+	for _, snap := range removed {
+		if repo.BaselineSnapshot == snap.ID {
+			if err := a.config.SetBaselineSnapshot(repo.ID, ""); err != nil {
+				a.logWarning("Failed to remove baseline snapshot: " + err.Error())
+			}
+		}
+		if repo.SnapshotNotes[snap.ID] != "" {
+			if err := a.config.SetSnapshotNote(repo.ID, snap.ID, ""); err != nil {
+				a.logWarning("Failed to remove snapshot note: " + err.Error())
+			}
+		}
+	}
+}
+
+// ── Restore API ───────────────────────────────────────────────────
+
+func (a *App) StartRestore(repoID, snapshotID, targetPath string, verify bool) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+
+	entry := history.RestoreEntry{
+		RepoID:     repo.ID,
+		RepoName:   repo.Name,
+		SnapshotID: snapshotID,
+		Target:     targetPath,
+		Verify:     verify,
+	}
+	return a.startRestore(repo, history.Manifest{Restore: entry})
+}
+
+// ResumeRestore re-runs an interrupted restore recorded by GetRestoreManifests.
+// restic skips files that were already restored with identical content.
+func (a *App) ResumeRestore(manifestID string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	if a.manifests == nil {
+		return fmt.Errorf("restore manifest not found")
+	}
+	m, err := a.manifests.Get(manifestID)
+	if err != nil {
+		return err
+	}
+	repo, ok := a.config.GetRepository(m.Restore.RepoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	return a.startRestore(repo, m)
+}
+
+// GetRestoreManifests lists interrupted restores that can be resumed, newest first
+func (a *App) GetRestoreManifests() []history.Manifest {
+	if a.manifests == nil {
+		return []history.Manifest{}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	interrupted := []history.Manifest{}
+	for _, m := range a.manifests.List() {
+		if !a.restoring[m.ID] {
+			interrupted = append(interrupted, m)
+		}
+	}
+	return interrupted
+}
+
+// DiscardRestoreManifest forgets an interrupted restore and deletes the
+// partially restored data in its temp dir
+func (a *App) DiscardRestoreManifest(manifestID string) error {
+	if a.manifests == nil {
+		return nil
+	}
+	if m, err := a.manifests.Get(manifestID); err == nil && m.TempDir != "" {
+		if err := os.RemoveAll(m.TempDir); err != nil {
+			return fmt.Errorf("Failed to remove temp directory: %w", err)
+		}
+	}
+	return a.manifests.Delete(manifestID)
+}
+
+// manifestSaveInterval begrenzt, wie oft der Fortschritt ins Manifest geschrieben wird
+const manifestSaveInterval = 5 * time.Second
+
+// startRestore records a manifest for m and runs the restore in the
+// background. The manifest is removed once the restore has succeeded, so
+// failed or cancelled restores can be resumed.
+func (a *App) startRestore(repo config.Repository, m history.Manifest) error {
+	if m.Restore.ToOriginal {
+		if err := a.refuseInSafeMode(); err != nil {
+			return err
+		}
+	}
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+		m.Started = time.Now()
+	}
+	if err := a.beginRestore(m.ID); err != nil {
+		return err
+	}
+	a.touchRecentRepo(repo.ID)
+	if m.TempDir == "" {
+		m.TempDir = restoreTempDir(m)
+	}
+	a.saveManifest(&m)
+
+	entry := m.Restore
+	a.goOp(func() {
+		defer func() {
+			a.mu.Lock()
+			delete(a.restoring, m.ID)
+			a.mu.Unlock()
+		}()
+
+		lastSave := time.Now()
+		var summary restic.RestoreProgress
+		failures := []FileError{}
+		onLine := func(line string) {
+			var progress restic.RestoreProgress
+			if jsonErr := json.Unmarshal([]byte(line), &progress); jsonErr != nil {
+				return
+			}
+			if progress.MessageType == "error" {
+				var e restic.ErrorUpdate
+				if json.Unmarshal([]byte(line), &e) == nil && e.Item != "" {
+					failures = append(failures, FileError{Path: e.Item, Message: e.Error.Message})
+				}
+				return
+			}
+			// Ältere restic-Versionen senden keine Summary: dann zählt der letzte Status
+			if progress.MessageType == "summary" || (progress.MessageType == "status" && summary.MessageType != "summary") {
+				summary = progress
+			}
+			runtime.EventsEmit(a.ctx, "restore:progress", progress)
+			m.FilesDone, m.TotalFiles = progress.FilesRestored+progress.FilesSkipped, progress.TotalFiles
+			m.BytesDone, m.TotalBytes = progress.BytesRestored+progress.BytesSkipped, progress.TotalBytes
+			if time.Since(lastSave) >= manifestSaveInterval {
+				lastSave = time.Now()
+				a.saveManifest(&m)
+			}
+		}
+
+		var err error
+		switch {
+		case entry.ToOriginal:
+			err = a.restoreToOriginal(repo, entry.SnapshotID, entry.Paths, m.TempDir, entry.Verify, onLine)
+		case m.Flatten:
+			err = a.restoreFlattened(repo, entry.SnapshotID, entry.Paths, entry.Target, m.TempDir, entry.Verify, onLine)
+		default:
+			err = a.runRestore(repo, entry.SnapshotID, entry.Target, entry.Paths, entry.Verify, onLine)
+		}
+		if entry.Verify {
+			runtime.EventsEmit(a.ctx, "restore:verify", RestoreVerification{Passed: err == nil && len(failures) == 0, Failures: failures})
+			if err != nil && len(failures) > 0 {
+				err = fmt.Errorf("%d file(s) failed to restore or verify: %w", len(failures), err)
+			}
+		}
+
+		if err == nil && a.manifests != nil {
+			if delErr := a.manifests.Delete(m.ID); delErr != nil {
+				runtime.LogWarning(a.ctx, "Failed to remove restore manifest: "+delErr.Error())
+			}
+		} else {
+			a.saveManifest(&m)
+		}
+		summary.MessageType = "summary"
+		a.finishRestore(entry, summary, err)
+	})
+	return nil
+}
+
+// beginRestore markiert id als laufende Wiederherstellung. Prüfung und
+// Markierung geschehen in einem kritischen Abschnitt unter a.mu; runCheck
+// und queueBackup (beim Einreihen) halten dieselbe Sperre, daher kommen
+// zwei schnelle Aufrufe nie beide durch.
+func (a *App) beginRestore(id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.restoring[id] {
+		return fmt.Errorf("this restore is already running")
+	}
+	if len(a.restoring) > 0 || a.checking || a.jobsActive() {
+		return ErrBusy
+	}
+	if a.restoring == nil {
+		a.restoring = map[string]bool{}
+	}
+	a.restoring[id] = true
+	return nil
+}
+
+// saveManifest schreibt m; Fehler werden nur protokolliert
+func (a *App) saveManifest(m *history.Manifest) {
+	if a.manifests == nil {
+		return
+	}
+	m.Updated = time.Now()
+	if err := a.manifests.Save(*m); err != nil {
+		runtime.LogWarning(a.ctx, "Failed to save restore manifest: "+err.Error())
+	}
+}
+
+func (a *App) CancelRestore() {
+	if a.runner != nil {
+		a.runner.Cancel()
+	}
+}
+
+// CancelCommands stops running one-shot commands such as a connection test,
+// init or stats; queued backup jobs are cancelled via CancelJob instead
+func (a *App) CancelCommands() {
+	if a.runner != nil {
+		a.runner.Cancel()
+	}
+}
+
+// cancelOperations bricht Jobs, Listings, Größenabfragen und alle
+// restic-Befehle ab (für shutdown und CancelAll)
+func (a *App) cancelOperations() {
+	if a.jobs != nil {
+		a.jobs.CancelKind("")
+	}
+	a.CancelSnapshotEnrichment()
+	a.CancelSnapshotListing()
+	if a.runner != nil {
+		a.runner.Cancel()
+	}
+}
+
+// cancelAllPoll: so oft prüft CancelAll, ob alles beendet ist
+const cancelAllPoll = 100 * time.Millisecond
+
+// CancelAll stops everything at once: queued and running jobs, restores,
+// checks, snapshot listings and size queries. Temp folders and pattern files
+// are removed by the operations themselves as they unwind. "all:cancelled"
+// is emitted once they have finished (true) or after 10 seconds (false).
+func (a *App) CancelAll() {
+	a.cancelOperations()
+	go func() {
+		deadline := time.Now().Add(shutdownTimeout)
+		for a.busy() && time.Now().Before(deadline) {
+			time.Sleep(cancelAllPoll)
+		}
+		runtime.EventsEmit(a.ctx, "all:cancelled", !a.busy())
+	}()
+}
+
+// ── Restic Info ───────────────────────────────────────────────────
+
+// GetResticStatus returns the restic path if found, or an error message
+func (a *App) GetResticStatus() map[string]string {
+	if a.runner == nil {
+		return map[string]string{
+			"found":   "false",
+			"message": i18n.T("restic.notFound"),
+		}
+	}
+	return map[string]string{
+		"found": "true",
+		"path":  a.runner.ResticPath(),
+	}
+}
+
+// GetLanguage returns the active UI language and all bundled languages
+func (a *App) GetLanguage() map[string]interface{} {
+	return map[string]interface{}{
+		"language":  i18n.Language(),
+		"available": i18n.Languages(),
+	}
+}
+
+// SetLanguage switches the language of messages and persists the choice
+func (a *App) SetLanguage(lang string) error {
+	i18n.SetLanguage(lang)
+	return a.config.SetLanguage(i18n.Language())
+}
+
+// GetTranslations returns all messages of the active language for the UI
+func (a *App) GetTranslations() map[string]string {
+	return i18n.All()
+}
+
+func (a *App) GetResticVersion() string {
+	if a.runner == nil {
+		return "restic not found"
+	}
+	v, err := a.resticVersion()
+	if err != nil {
+		return "?"
+	}
+	return v.String()
+}
+
+// GetResticVersionInfo returns the structured version of the restic binary
+func (a *App) GetResticVersionInfo() (restic.VersionInfo, error) {
+	if a.runner == nil {
+		return restic.VersionInfo{}, fmt.Errorf("restic not found")
+	}
+	return a.resticVersion()
+}
+
+// resticVersion ermittelt die Version einmalig; das Binary ändert sich zur Laufzeit nicht
+func (a *App) resticVersion() (restic.VersionInfo, error) {
+	a.mu.Lock()
+	cached := a.version
+	a.mu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	v, err := restic.DetectVersion(a.runner)
+	if err != nil {
+		return v, err
+	}
+	a.mu.Lock()
+	a.version = &v
+	a.mu.Unlock()
+	return v, nil
+}
+
+// ResticUpdate ist das Ergebnis von CheckResticUpdate
+type ResticUpdate struct {
+	Current   string `json:"current"`
+	Latest    string `json:"latest"` // leer, wenn nicht geprüft wurde
+	Available bool   `json:"available"`
+	Checked   bool   `json:"checked"` // false = Prüfung ist abgeschaltet
+}
+
+// releaseCheckTimeout begrenzt die Abfrage bei GitHub
+const releaseCheckTimeout = 10 * time.Second
+
+// CheckResticUpdate compares the installed restic with the latest GitHub
+// release. Nothing is downloaded or installed; without SetCheckResticUpdates
+// no network request is made.
+func (a *App) CheckResticUpdate() (ResticUpdate, error) {
+	if a.runner == nil {
+		return ResticUpdate{}, fmt.Errorf("restic not found")
+	}
+	v, err := a.resticVersion()
+	if err != nil {
+		return ResticUpdate{}, err
+	}
+	update := ResticUpdate{Current: v.Version}
+	if !a.config.GetCheckResticUpdates() {
+		return update, nil
+	}
+	ctx, cancel := context.WithTimeout(a.ctx, releaseCheckTimeout)
+	defer cancel()
+	latest, err := restic.LatestRelease(ctx)
+	if err != nil {
+		return update, err
+	}
+	update.Latest, update.Checked = latest, true
+	update.Available = v.OlderThan(latest)
+	return update, nil
+}
+
+// GetCheckResticUpdates reports whether CheckResticUpdate may query GitHub
+func (a *App) GetCheckResticUpdates() bool {
+	return a.config.GetCheckResticUpdates()
+}
+
+// SetCheckResticUpdates allows or forbids the GitHub query of CheckResticUpdate
+func (a *App) SetCheckResticUpdates(enabled bool) error {
+	return a.config.SetCheckResticUpdates(enabled)
+}
+
+// UpdateRestic updates the restic binary next to the app via restic
+// self-update and returns the new version. restic from PATH (package
+// manager, system install) is left alone.
+func (a *App) UpdateRestic() (restic.VersionInfo, error) {
+	if a.runner == nil {
+		return restic.VersionInfo{}, fmt.Errorf("restic not found")
+	}
+	if err := a.refuseInSafeMode(); err != nil {
+		return restic.VersionInfo{}, err
+	}
+	current := a.runner.ResticPath()
+	exe, err := os.Executable()
+	if err != nil || !strings.EqualFold(filepath.Dir(current), filepath.Dir(exe)) {
+		return restic.VersionInfo{}, fmt.Errorf("restic at %s is not the app's bundled copy; update it with your package manager", current)
+	}
+	if a.busy() {
+		return restic.VersionInfo{}, fmt.Errorf("restic is in use; wait for running backups and restores to finish")
+	}
+
+	// Windows sperrt laufende Programme gegen Überschreiben, erlaubt aber das
+	// Umbenennen: neue Version daneben laden und dann die Dateien tauschen
+	next, old := current+".new", current+".old"
+	os.Remove(old)
+	if _, err := a.runner.Run(restic.Repo{}, []string{"self-update", "--output=" + next}); err != nil {
+		os.Remove(next)
+		return restic.VersionInfo{}, err
+	}
+	// Ist restic schon aktuell, legt self-update keine Datei an
+	if _, err := os.Stat(next); err != nil {
+		return a.resticVersion()
+	}
+	if err := os.Rename(current, old); err != nil {
+		os.Remove(next)
+		return restic.VersionInfo{}, fmt.Errorf("failed to replace restic: %w", err)
+	}
+	if err := os.Rename(next, current); err != nil {
+		os.Rename(old, current)
+		return restic.VersionInfo{}, fmt.Errorf("failed to replace restic: %w", err)
+	}
+	os.Remove(old) // kann noch gesperrt sein; wird beim nächsten Update entfernt
+
+	a.mu.Lock()
+	a.version = nil
+	a.mu.Unlock()
+	return a.resticVersion()
+}
+
+// RunResticCommand runs an allowlisted read-only restic subcommand against
+// a repository and returns its combined output
+func (a *App) RunResticCommand(repoID string, args []string) (string, error) {
+	if a.runner == nil {
+		return "", fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return "", fmt.Errorf("repository not found")
+	}
+	if err := restic.ValidateReadOnlyCommand(args); err != nil {
+		return "", err
+	}
+	return a.runner.Run(resticRepo(repo), args)
+}
+
+// BuildResticCommand returns the command line the app would execute for
+// args against a repository, ready to paste into a terminal. The password
+// is redacted.
+func (a *App) BuildResticCommand(repoID string, args []string) (string, error) {
+	if a.runner == nil {
+		return "", fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return "", fmt.Errorf("repository not found")
+	}
+	return restic.FormatCommand(a.runner.ResticPath(), resticRepo(repo), args)
+}
+
+// ── Selective Restore ─────────────────────────────────────────────
+
+// ListSnapshotContents lists all files in a snapshot via restic ls --json
+func (a *App) ListSnapshotContents(repoID, snapshotID string) ([]restic.FileNode, error) {
+	if a.runner == nil {
+		return nil, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return nil, fmt.Errorf("repository not found")
+	}
+
+	key := repo.URI + "\x00" + snapshotID
+	if nodes, ok := a.cachedContents(key); ok {
+		return nodes, nil
+	}
+
+	ctx, done := a.registerListing()
+	defer done()
+
+	// ls can print millions of lines: stream instead of buffering the output
+	args := []string{"ls", "--json", snapshotID}
+	var nodes []restic.FileNode
+	err := a.runner.RunWithProgressContext(ctx, resticRepo(repo), args, func(line string) {
+		if line == "" || ctx.Err() != nil {
+			return
+		}
+		var node restic.FileNode
+		if err := json.Unmarshal([]byte(line), &node); err != nil {
+			return
+		}
+		// First line is snapshot info; file/dir nodes have struct_type "node"
+		if node.StructType == "node" {
+			nodes = append(nodes, node)
+			if len(nodes)%lsProgressEvery == 0 {
+				runtime.EventsEmit(a.ctx, "ls:progress", ListProgress{SnapshotID: snapshotID, Entries: len(nodes)})
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if snapshotID != "latest" {
+		a.cacheContents(key, nodes)
+	}
+	return nodes, nil
+}
+
+// ListProgress wird während ListSnapshotContents als "ls:progress" gesendet
+type ListProgress struct {
+	SnapshotID string `json:"snapshotId"`
+	Entries    int    `json:"entries"` // bisher gelesene Einträge
+}
+
+// lsProgressEvery: nach so vielen Einträgen folgt ein "ls:progress"
+const lsProgressEvery = 10000
+
+// registerListing legt einen Context an, den CancelSnapshotListing abbrechen kann
+func (a *App) registerListing() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.mu.Lock()
+	if a.lsCancels == nil {
+		a.lsCancels = map[int]context.CancelFunc{}
+	}
+	a.nextLsID++
+	id := a.nextLsID
+	a.lsCancels[id] = cancel
+	a.mu.Unlock()
+	return ctx, func() {
+		a.mu.Lock()
+		delete(a.lsCancels, id)
+		a.mu.Unlock()
+		cancel()
+	}
+}
+
+// CancelSnapshotListing stops all running ListSnapshotContents calls; they
+// return restic.ErrCancelled
+func (a *App) CancelSnapshotListing() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, cancel := range a.lsCancels {
+		cancel()
+	}
+}
+
+// cachedContents liefert eine zwischengespeicherte ls-Ausgabe
+func (a *App) cachedContents(key string) ([]restic.FileNode, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, e := range a.lsCache {
+		if e.key == key {
+			return e.nodes, true
+		}
+	}
+	return nil, false
+}
+
+// cacheContents speichert eine ls-Ausgabe und verdrängt den ältesten Eintrag
+func (a *App) cacheContents(key string, nodes []restic.FileNode) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lsCache = append(a.lsCache, lsCacheEntry{key: key, nodes: nodes})
+	if len(a.lsCache) > lsCacheSize {
+		a.lsCache = a.lsCache[len(a.lsCache)-lsCacheSize:]
+	}
+}
+
+// maxSuggestions begrenzt die Treffer von SuggestPaths
+const maxSuggestions = 50
+
+// SuggestPaths returns up to 50 entries of a snapshot whose path starts with
+// prefix, shallowest first, for autocomplete. The prefix may be written as a
+// local path ("C:\Users\…"); matching ignores case. The snapshot listing is
+// cached, so repeated calls while typing don't run restic again.
+func (a *App) SuggestPaths(repoID, snapshotID, prefix string) ([]restic.FileNode, error) {
+	nodes, err := a.ListSnapshotContents(repoID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.ToLower(resticPathPrefix(prefix))
+	matches := []restic.FileNode{}
+	for _, n := range nodes {
+		if strings.HasPrefix(strings.ToLower(n.Path), prefix) {
+			matches = append(matches, n)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		di, dj := strings.Count(matches[i].Path, "/"), strings.Count(matches[j].Path, "/")
+		if di != dj {
+			return di < dj
+		}
+		return matches[i].Path < matches[j].Path
+	})
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	return matches, nil
+}
+
+// resticPathPrefix schreibt eine Eingabe in restics Pfadform um:
+// "C:\Users" → "/C/Users", fehlender führender Slash wird ergänzt
+func resticPathPrefix(p string) string {
+	p = strings.ReplaceAll(strings.TrimSpace(p), `\`, "/")
+	if len(p) >= 2 && p[1] == ':' {
+		p = "/" + p[:1] + p[2:]
+	}
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+// GetRecentFiles returns up to limit files from the latest snapshot of a
+// repository, most recently modified first
+func (a *App) GetRecentFiles(repoID string, limit int) ([]restic.FileNode, error) {
+	snapshots, err := a.GetSnapshots(repoID)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return []restic.FileNode{}, nil
+	}
+	latest := snapshots[0]
+	for _, s := range snapshots[1:] {
+		if snapshotTime(s).After(snapshotTime(latest)) {
+			latest = s
+		}
+	}
+	nodes, err := a.ListSnapshotContents(repoID, latest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]restic.FileNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Type == "file" {
+			files = append(files, n)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return nodeTime(files[i]).After(nodeTime(files[j]))
+	})
+	if limit > 0 && len(files) > limit {
+		files = files[:limit]
+	}
+	return files, nil
+}
+
+// snapshotTime parst den Zeitstempel eines Snapshots (Nullzeit bei Fehler)
+func snapshotTime(s restic.Snapshot) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s.Time)
+	return t
+}
+
+// nodeTime parst die mtime eines Snapshot-Eintrags (Nullzeit bei Fehler)
+func nodeTime(n restic.FileNode) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, n.MTime)
+	return t
+}
+
+// DiskDiff beschreibt eine Datei, die sich zwischen Snapshot und Festplatte unterscheidet
+type DiskDiff struct {
+	Path          string    `json:"path"`      // Pfad im Snapshot
+	LocalPath     string    `json:"localPath"` // Originalpfad auf der Festplatte
+	Status        string    `json:"status"`    // "missing" oder "changed"
+	SnapshotSize  uint64    `json:"snapshotSize"`
+	DiskSize      uint64    `json:"diskSize"`
+	SnapshotMTime time.Time `json:"snapshotMTime"`
+	DiskMTime     time.Time `json:"diskMTime"`
+	DiskNewer     bool      `json:"diskNewer"` // Datei wurde nach dem Backup geändert
+}
+
+// mtimeTolerance absorbs timestamp precision differences between filesystems
+const mtimeTolerance = time.Second
+
+// DiffSnapshotWithDisk compares the files below paths in a snapshot with
+// the files currently at their original location and returns those a
+// restore to original would change. An empty paths list compares everything.
+func (a *App) DiffSnapshotWithDisk(repoID, snapshotID string, paths []string) ([]DiskDiff, error) {
+	nodes, err := a.ListSnapshotContents(repoID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	diffs := []DiskDiff{}
+	for _, node := range nodes {
+		if node.Type != "file" || !underAny(node.Path, paths) {
+			continue
+		}
+		snapMTime := nodeTime(node)
+		d := DiskDiff{
+			Path:          node.Path,
+			LocalPath:     localPath(node.Path),
+			SnapshotSize:  node.Size,
+			SnapshotMTime: snapMTime,
+		}
+		info, err := os.Stat(d.LocalPath)
+		if err != nil {
+			d.Status = "missing"
+			diffs = append(diffs, d)
+			continue
+		}
+		d.DiskSize = uint64(info.Size())
+		d.DiskMTime = info.ModTime()
+		delta := d.DiskMTime.Sub(snapMTime)
+		if d.DiskSize == d.SnapshotSize && delta < mtimeTolerance && delta > -mtimeTolerance {
+			continue
+		}
+		d.Status = "changed"
+		d.DiskNewer = delta >= mtimeTolerance
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// maxCatSize begrenzt, wie viel von einer Datei CatFile liest
+const maxCatSize = 1 << 20
+
+// FileContent ist das Ergebnis von CatFile
+type FileContent struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`  // Text oder Base64 bei Binärdateien
+	Encoding  string `json:"encoding"` // "text" oder "base64"
+	Binary    bool   `json:"binary"`
+	Truncated bool   `json:"truncated"` // Datei ist größer als maxCatSize
+}
+
+// CatFile returns the contents of a single file from a snapshot via
+// restic dump without restoring it. Only the first 1 MB is read; binary
+// files are returned base64-encoded.
+func (a *App) CatFile(repoID, snapshotID, path string) (FileContent, error) {
+	if a.runner == nil {
+		return FileContent{}, fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return FileContent{}, fmt.Errorf("repository not found")
+	}
+	if path == "" {
+		return FileContent{}, fmt.Errorf("no file selected")
+	}
+
+	buf := &cappedWriter{max: maxCatSize}
+	err := a.runner.RunToWriter(resticRepo(repo), []string{"dump", "--", snapshotID, path}, buf)
+	// Nach maxCatSize bricht der Schreibfehler restic ab: das ist kein Fehler
+	if err != nil && !buf.truncated {
+		return FileContent{}, err
+	}
+
+	content := FileContent{Path: path, Truncated: buf.truncated, Binary: isBinary(buf.buf, buf.truncated)}
+	if content.Binary {
+		content.Encoding, content.Content = "base64", base64.StdEncoding.EncodeToString(buf.buf)
+	} else {
+		content.Encoding, content.Content = "text", string(buf.buf)
+	}
+	return content, nil
+}
+
+// ExportProgress wird während ExportSnapshotArchive als "export:progress" gesendet
+type ExportProgress struct {
+	OutputFile   string `json:"outputFile"`
+	BytesWritten int64  `json:"bytesWritten"`
+}
+
+// exportProgressInterval drosselt die "export:progress"-Events
+const exportProgressInterval = 250 * time.Millisecond
+
+// ExportSnapshotArchive writes path from a snapshot (empty = everything) into
+// a single tar or zip file via restic dump --archive. Progress is reported
+// as "export:progress"; a failed export removes the incomplete file.
+// Safe mode refuses to overwrite an existing file.
+func (a *App) ExportSnapshotArchive(repoID, snapshotID, path, outputFile, format string) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	if format != "tar" && format != "zip" {
+		return fmt.Errorf("invalid archive format %q, expected tar or zip", format)
+	}
+	if outputFile == "" {
+		return fmt.Errorf("no output file selected")
+	}
+	// Eine vorhandene Datei würde überschrieben (und bei einem Fehler gelöscht)
+	if _, err := os.Stat(outputFile); err == nil {
+		if err := a.refuseInSafeMode(); err != nil {
+			return err
+		}
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	progress := ExportProgress{OutputFile: outputFile}
+	var last time.Time
+	w := &progressWriter{w: f, onWrite: func(n int64) {
+		progress.BytesWritten += n
+		if time.Since(last) >= exportProgressInterval {
+			last = time.Now()
+			runtime.EventsEmit(a.ctx, "export:progress", progress)
+		}
+	}}
+	args := []string{"dump", "--archive=" + format, "--", snapshotID, path}
+	err = a.runner.RunToWriter(resticRepo(repo), args, w)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(outputFile)
+		return err
+	}
+	runtime.EventsEmit(a.ctx, "export:progress", progress)
+	return nil
+}
+
+// cappedWriter sammelt bis zu max Bytes und lehnt danach weitere Daten ab,
+// damit der Schreiber (restic) abbricht
+type cappedWriter struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	if room := w.max - len(w.buf); len(p) > room {
+		w.buf = append(w.buf, p[:room]...)
+		w.truncated = true
+		return room, io.ErrShortWrite
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+// isBinary hält Daten mit NUL-Bytes oder ungültigem UTF-8 für binär. Bei
+// abgeschnittenen Daten darf das letzte Zeichen unvollständig sein.
+func isBinary(data []byte, truncated bool) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	if truncated {
+		for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(data[i]) {
+				if !utf8.FullRune(data[i:]) {
+					data = data[:i]
+				}
+				break
+			}
+		}
+	}
+	return !utf8.Valid(data)
+}
+
+// RestoreSelected restores selected paths from a snapshot.
+// toOriginal=true  → temp dir on SAME drive → fast os.Rename to original path
+// toOriginal=false → restore directly to targetPath
+// flatten=true     → custom target only: selected entries land directly in targetPath
+func (a *App) RestoreSelected(repoID, snapshotID string, includePaths []string, targetPath string, toOriginal, flatten, verify bool) error {
+	if a.runner == nil {
+		return fmt.Errorf("restic not found")
+	}
+	repo, ok := a.config.GetRepository(repoID)
+	if !ok {
+		return fmt.Errorf("repository not found")
+	}
+	if len(includePaths) == 0 {
+		return fmt.Errorf("no paths selected")
+	}
+
+	entry := history.RestoreEntry{
+		RepoID:     repo.ID,
+		RepoName:   repo.Name,
+		SnapshotID: snapshotID,
+		Paths:      includePaths,
+		Target:     targetPath,
+		ToOriginal: toOriginal,
+		Verify:     verify,
+	}
+
+	return a.startRestore(repo, history.Manifest{Restore: entry, Flatten: flatten && !toOriginal})
+}
+
+// GetRestoreConflicts returns the files below paths that were changed on
+// disk after the snapshot was taken; a restore to original would overwrite
+// these edits. Builds on DiffSnapshotWithDisk, so the listing is cached.
+func (a *App) GetRestoreConflicts(repoID, snapshotID string, paths []string) ([]DiskDiff, error) {
+	diffs, err := a.DiffSnapshotWithDisk(repoID, snapshotID, paths)
+	if err != nil {
+		return nil, err
+	}
+	conflicts := []DiskDiff{}
+	for _, d := range diffs {
+		if d.Status == "changed" && d.DiskNewer {
+			conflicts = append(conflicts, d)
+		}
+	}
+	return conflicts, nil
+}
+
+// RestoreResolved restores paths to their original location like
+// RestoreSelected, but skips every conflict (see GetRestoreConflicts) that is
+// not listed in overwrite. Conflicts are determined again here, so a file
+// edited after the user decided is kept as well. With skipped files the
+// selection is passed to restic file by file, so empty folders below the
+// selection are not recreated.
+func (a *App) RestoreResolved(repoID, snapshotID string, paths, overwrite []string, verify bool) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths selected")
+	}
+	conflicts, err := a.GetRestoreConflicts(repoID, snapshotID, paths)
+	if err != nil {
+		return err
+	}
+	approved := make(map[string]bool, len(overwrite))
+	for _, p := range overwrite {
+		approved[p] = true
+	}
+	skip := map[string]bool{}
+	for _, c := range conflicts {
+		if !approved[c.Path] {
+			skip[c.Path] = true
+		}
+	}
+	if len(skip) == 0 {
+		return a.RestoreSelected(repoID, snapshotID, paths, "", true, false, verify)
+	}
+
+	nodes, err := a.ListSnapshotContents(repoID, snapshotID)
+	if err != nil {
+		return err
+	}
+	var include []string
+	for _, n := range nodes {
+		if n.Type != "dir" && underAny(n.Path, paths) && !skip[n.Path] {
+			include = append(include, n.Path)
+		}
 	}
-	repo, ok := a.config.GetRepository(id)
-	if !ok {
-		return "", fmt.Errorf("repository not found")
+	if len(include) == 0 {
+		return fmt.Errorf("all selected files were skipped")
 	}
-	out, err := a.runner.Run(repo.URI, repo.Password, []string{"cat", "config"})
-	if err != nil {
-		return "", err
+	return a.RestoreSelected(repoID, snapshotID, include, "", true, false, verify)
+}
+
+// restoreTempDir wählt den Zwischenordner für Flatten und ToOriginal; er
+// wird im Manifest gespeichert, damit ResumeRestore dort weitermacht.
+// Leer, wenn kein Zwischenordner nötig ist oder das Laufwerk unbekannt ist.
+func restoreTempDir(m history.Manifest) string {
+	suffix := uuid.New().String()[:8]
+	switch {
+	case m.Restore.ToOriginal:
+		if len(m.Restore.Paths) == 0 {
+			return ""
+		}
+		if drive := extractDriveLetter(m.Restore.Paths[0]); drive != "" {
+			return drive + `:\restic-gui-temp-` + suffix
+		}
+	case m.Flatten:
+		return filepath.Join(m.Restore.Target, ".restic-gui-temp-"+suffix)
 	}
-	_ = out
-	return "Connection successful!", nil
+	return ""
 }
 
-func (a *App) InitRepository(repo config.Repository) error {
-	if a.runner == nil {
-		return fmt.Errorf("restic not found")
+// removeTempDir löscht den Zwischenordner nach erfolgreichem Restore; ein
+// Fehler dabei macht den Restore nicht ungültig und wird nur protokolliert
+func removeTempDir(ctx context.Context, dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		runtime.LogWarning(ctx, "Failed to remove temp directory: "+err.Error())
 	}
-	_, err := a.runner.Run(repo.URI, repo.Password, []string{"init"})
-	return err
 }
 
-// ── Dateiauswahl ─────────────────────────────────────────────────
+// restoreFlattened restores into tempDir inside targetPath (same drive →
+// fast rename) and then moves only the selected entries into targetPath.
+// Name clashes between entries from different folders get a " (n)" suffix.
+// tempDir is kept on failure so a resumed restore can continue there.
+func (a *App) restoreFlattened(repo config.Repository, snapshotID string, includePaths []string, targetPath, tempDir string, verify bool, onLine func(string)) error {
+	if tempDir == "" {
+		return fmt.Errorf("no temp directory for the restore")
+	}
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return fmt.Errorf("Failed to create temp directory: %w", err)
+	}
 
-func (a *App) SelectFolders() ([]string, error) {
-	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select source folder",
-	})
-	if err != nil || dir == "" {
-		return nil, err
+	if err := a.runRestore(repo, snapshotID, tempDir, includePaths, verify, onLine); err != nil {
+		return err
 	}
-	return []string{dir}, nil
-}
 
-func (a *App) SelectRestoreFolder() (string, error) {
-	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select restore target folder",
-	})
-	return dir, err
+	used := map[string]int{}
+	for _, p := range topLevelPaths(includePaths) {
+		name := filepath.Base(filepath.FromSlash(p))
+		used[strings.ToLower(name)]++
+		if n := used[strings.ToLower(name)]; n > 1 {
+			ext := filepath.Ext(name)
+			name = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+		}
+		src := filepath.Join(tempDir, filepath.FromSlash(strings.TrimPrefix(p, "/")))
+		if err := moveEntry(src, filepath.Join(targetPath, name), func(p CopyProgress) {
+			runtime.EventsEmit(a.ctx, "restore:copying", p)
+		}); err != nil {
+			return fmt.Errorf("Move failed: %w", err)
+		}
+	}
+	removeTempDir(a.ctx, tempDir)
+	return nil
 }
 
-// ── Backup API ────────────────────────────────────────────────────
-
-func (a *App) StartBackup(job restic.BackupJob) error {
-	if a.runner == nil {
-		return fmt.Errorf("restic not found")
+// restoreToOriginal restores includePaths to where they were backed up from.
+// Restic stores Windows paths as /G/folder (drive letter = first dir).
+// Strategy: restore to tempDir on SAME drive → os.Rename (no copy needed).
+// tempDir is kept on failure so a resumed restore can continue there.
+func (a *App) restoreToOriginal(repo config.Repository, snapshotID string, includePaths []string, tempDir string, verify bool, onLine func(string)) error {
+	// Extract drive letter from first include path: e.g. "/G/..." → "G"
+	driveLetter := extractDriveLetter(includePaths[0])
+	if driveLetter == "" || tempDir == "" {
+		return fmt.Errorf("Could not determine drive letter from path")
 	}
-	repo, ok := a.config.GetRepository(job.RepoID)
-	if !ok {
-		return fmt.Errorf("repository not found")
+
+	// Bis zum Verschieben liegen die Dateien zusätzlich im Temp-Ordner:
+	// die volle Restore-Größe muss auf dem Laufwerk frei sein
+	if err := a.checkFreeSpace(repo, snapshotID, includePaths, driveLetter+`:\`); err != nil {
+		return err
 	}
 
-	args := []string{"backup", "--json"}
-	for _, ex := range job.Excludes {
-		args = append(args, "--exclude", ex)
+	// Temp dir on SAME drive (restoreTempDir): e.g. G:\restic-gui-temp-<uuid>
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return fmt.Errorf("Failed to create temp directory: %w", err)
 	}
-	for _, tag := range job.Tags {
-		args = append(args, "--tag", tag)
+
+	// Restore in Temp: Ergebnis z.B. tempDir\G\namDHC_v113
+	if err := a.runRestore(repo, snapshotID, tempDir, includePaths, verify, onLine); err != nil {
+		return err
 	}
-	args = append(args, job.SourcePaths...)
 
-	go func() {
-		err := a.runner.RunWithProgress(repo.URI, repo.Password, args, func(line string) {
-			var progress restic.BackupProgress
-			if jsonErr := json.Unmarshal([]byte(line), &progress); jsonErr == nil {
-				runtime.EventsEmit(a.ctx, "backup:progress", progress)
-			}
-		})
-		if err != nil {
-			runtime.EventsEmit(a.ctx, "backup:error", err.Error())
-		} else {
-			runtime.EventsEmit(a.ctx, "backup:complete", nil)
-		}
-	}()
+	// tempDir\G\* → G:\*  (fast rename on same drive)
+	srcBase := filepath.Join(tempDir, driveLetter)
+	dstBase := driveLetter + `:\`
+	if err := moveContents(srcBase, dstBase, func(p CopyProgress) {
+		runtime.EventsEmit(a.ctx, "restore:copying", p)
+	}); err != nil {
+		return fmt.Errorf("Move failed: %w", err)
+	}
+	removeTempDir(a.ctx, tempDir)
 	return nil
 }
 
-func (a *App) CancelBackup() {
-	if a.runner != nil {
-		a.runner.Cancel()
+// checkFreeSpace schätzt die Restore-Größe aus der (meist zwischengespeicherten)
+// ls-Ausgabe und vergleicht sie mit dem freien Platz unter drive. Lässt sich
+// eins von beiden nicht ermitteln, wird nicht blockiert.
+func (a *App) checkFreeSpace(repo config.Repository, snapshotID string, includePaths []string, drive string) error {
+	nodes, err := a.ListSnapshotContents(repo.ID, snapshotID)
+	if err != nil {
+		return nil
+	}
+	var needed uint64
+	for _, n := range nodes {
+		if n.Type == "file" && underAny(n.Path, includePaths) {
+			needed += n.Size
+		}
+	}
+	free, err := freeSpace(drive)
+	if err != nil || free >= needed {
+		return nil
 	}
+	return fmt.Errorf("not enough free space on %s: the restore needs %.1f GB, only %.1f GB are free",
+		drive, float64(needed)/1e9, float64(free)/1e9)
 }
 
-// ── Snapshot API ──────────────────────────────────────────────────
-
-func (a *App) GetSnapshots(repoID string) ([]restic.Snapshot, error) {
-	if a.runner == nil {
-		return nil, fmt.Errorf("restic not found")
+// finishRestore records a finished restore in the history and notifies the
+// UI; on success "restore:complete" carries the final totals
+func (a *App) finishRestore(entry history.RestoreEntry, summary restic.RestoreProgress, err error) {
+	entry.Time = time.Now()
+	entry.Success = err == nil
+	if err != nil {
+		entry.Error = err.Error()
 	}
-	repo, ok := a.config.GetRepository(repoID)
-	if !ok {
-		return nil, fmt.Errorf("repository not found")
+	if a.restores != nil {
+		if histErr := a.restores.Add(entry); histErr != nil {
+			runtime.LogWarning(a.ctx, "Failed to record restore: "+histErr.Error())
+		}
 	}
-	out, err := a.runner.Run(repo.URI, repo.Password, []string{"snapshots", "--json"})
 	if err != nil {
-		return nil, err
+		runtime.EventsEmit(a.ctx, "restore:error", err.Error())
+	} else {
+		runtime.EventsEmit(a.ctx, "restore:complete", summary)
 	}
-	var snapshots []restic.Snapshot
-	if err := json.Unmarshal([]byte(out), &snapshots); err != nil {
-		return nil, fmt.Errorf("failed to parse snapshot data")
+	a.notify("restore", entry.RepoName, err, "Snapshot "+shortID(entry.SnapshotID)+" to "+restoreTarget(entry)+restoreSummary(summary))
+}
+
+// restoreSummary fasst die Endstände für Benachrichtigungen zusammen
+func restoreSummary(s restic.RestoreProgress) string {
+	if s.FilesRestored == 0 && s.FilesSkipped == 0 {
+		return ""
 	}
-	return snapshots, nil
+	return fmt.Sprintf(": %d files (%d bytes) restored, %d skipped", s.FilesRestored, s.BytesRestored, s.FilesSkipped)
 }
 
-func (a *App) DeleteSnapshot(repoID, snapshotID string) error {
-	if a.runner == nil {
-		return fmt.Errorf("restic not found")
+// restoreTarget beschreibt das Ziel einer Wiederherstellung
+func restoreTarget(entry history.RestoreEntry) string {
+	if entry.ToOriginal {
+		return "original location"
 	}
-	repo, ok := a.config.GetRepository(repoID)
-	if !ok {
-		return fmt.Errorf("repository not found")
+	return entry.Target
+}
+
+// GetRestoreHistory returns all recorded restores, newest first
+func (a *App) GetRestoreHistory() []history.RestoreEntry {
+	if a.restores == nil {
+		return []history.RestoreEntry{}
 	}
-	_, err := a.runner.Run(repo.URI, repo.Password, []string{"forget", snapshotID, "--prune"})
-	return err
+	entries := a.restores.List()
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
 }
 
-// ── Restore API ───────────────────────────────────────────────────
+// TestRestoreResult wird nach einer Probe-Wiederherstellung als
+// "testrestore:complete" gesendet
+type TestRestoreResult struct {
+	RepoID          string      `json:"repoId"`
+	SnapshotID      string      `json:"snapshotId"`
+	Success         bool        `json:"success"`
+	Sampled         bool        `json:"sampled"` // nur eine Stichprobe, der Snapshot passte nicht in den Temp-Ordner
+	Files           uint64      `json:"files"`
+	Bytes           uint64      `json:"bytes"`
+	DurationSeconds float64     `json:"durationSeconds"`
+	Failures        []FileError `json:"failures"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// testRestoreReserve bleibt bei der Probe-Wiederherstellung im Temp-Ordner frei
+const testRestoreReserve = 1 << 30
 
-func (a *App) StartRestore(repoID, snapshotID, targetPath string) error {
+// TestRestore runs a restore drill: the snapshot is restored with --verify
+// into a temp dir that is deleted afterwards. If the snapshot does not fit
+// into the temp dir, a sample of its files is restored instead. Progress is
+// sent as "testrestore:progress", the result as "testrestore:complete".
+func (a *App) TestRestore(repoID, snapshotID string) error {
 	if a.runner == nil {
 		return fmt.Errorf("restic not found")
 	}
@@ -195,174 +3985,231 @@ func (a *App) StartRestore(repoID, snapshotID, targetPath string) error {
 	if !ok {
 		return fmt.Errorf("repository not found")
 	}
+	if snapshotID == "" {
+		return fmt.Errorf("no snapshot selected")
+	}
+	id := "test-" + uuid.New().String()
+	if err := a.beginRestore(id); err != nil {
+		return err
+	}
 
-	args := []string{"restore", snapshotID, "--target", targetPath, "--json"}
-
-	go func() {
-		err := a.runner.RunWithProgress(repo.URI, repo.Password, args, func(line string) {
-			var progress restic.RestoreProgress
-			if jsonErr := json.Unmarshal([]byte(line), &progress); jsonErr == nil {
-				runtime.EventsEmit(a.ctx, "restore:progress", progress)
-			}
-		})
+	a.goOp(func() {
+		defer func() {
+			a.mu.Lock()
+			delete(a.restoring, id)
+			a.mu.Unlock()
+		}()
+		started := time.Now()
+		res := TestRestoreResult{RepoID: repo.ID, SnapshotID: snapshotID, Failures: []FileError{}}
+		err := a.runTestRestore(repo, snapshotID, &res)
+		res.DurationSeconds = time.Since(started).Seconds()
+		if err == nil && len(res.Failures) > 0 {
+			err = fmt.Errorf("%d file(s) failed to restore or verify", len(res.Failures))
+		}
+		res.Success = err == nil
 		if err != nil {
-			runtime.EventsEmit(a.ctx, "restore:error", err.Error())
-		} else {
-			runtime.EventsEmit(a.ctx, "restore:complete", nil)
+			res.Error = err.Error()
 		}
-	}()
+		runtime.EventsEmit(a.ctx, "testrestore:complete", res)
+		summary := fmt.Sprintf("Snapshot %s: %d files (%d bytes) restored and verified", shortID(snapshotID), res.Files, res.Bytes)
+		if res.Sampled {
+			summary += " (sample)"
+		}
+		a.notify("test-restore", repo.Name, err, summary)
+	})
 	return nil
 }
 
-func (a *App) CancelRestore() {
-	if a.runner != nil {
-		a.runner.Cancel()
+// runTestRestore stellt in einen frischen Temp-Ordner wieder her und füllt res
+func (a *App) runTestRestore(repo config.Repository, snapshotID string, res *TestRestoreResult) error {
+	tempDir, err := os.MkdirTemp("", "restic-gui-testrestore-")
+	if err != nil {
+		return fmt.Errorf("Failed to create temp directory: %w", err)
 	}
-}
-
-// ── Restic Info ───────────────────────────────────────────────────
+	defer os.RemoveAll(tempDir)
 
-// GetResticStatus returns the restic path if found, or an error message
-func (a *App) GetResticStatus() map[string]string {
-	if a.runner == nil {
-		return map[string]string{
-			"found":   "false",
-			"message": "restic.exe not found.\n\nPlease do one of the following:\n  • Place restic.exe in the same folder as ResticBackupManager.exe\n  • Or install restic so it is available in your system PATH\n\nDownload: https://restic.net",
+	var include []string
+	if free, err := freeSpace(tempDir); err == nil {
+		nodes, lsErr := a.ListSnapshotContents(repo.ID, snapshotID)
+		if lsErr != nil {
+			return lsErr
+		}
+		var budget uint64
+		if free > testRestoreReserve {
+			budget = free - testRestoreReserve
+		}
+		include, res.Sampled = testRestoreSample(nodes, budget)
+		if res.Sampled && len(include) == 0 {
+			return fmt.Errorf("not enough free space in %s for a test restore", os.TempDir())
 		}
 	}
-	return map[string]string{
-		"found": "true",
-		"path":  a.runner.ResticPath(),
+
+	onLine := func(line string) {
+		var progress restic.RestoreProgress
+		if json.Unmarshal([]byte(line), &progress) != nil {
+			return
+		}
+		if progress.MessageType == "error" {
+			var e restic.ErrorUpdate
+			if json.Unmarshal([]byte(line), &e) == nil && e.Item != "" {
+				res.Failures = append(res.Failures, FileError{Path: e.Item, Message: e.Error.Message})
+			}
+			return
+		}
+		res.Files, res.Bytes = progress.FilesRestored, progress.BytesRestored
+		runtime.EventsEmit(a.ctx, "testrestore:progress", progress)
 	}
+	return a.runRestore(repo, snapshotID, tempDir, include, true, onLine)
 }
 
-func (a *App) GetResticVersion() string {
-	if a.runner == nil {
-		return "restic not found"
+// testRestoreSample liefert nil (alles wiederherstellen), wenn der Snapshot
+// in budget passt, sonst eine Stichprobe: jede n-te Datei, solange das
+// Budget reicht, damit möglichst viele Ordner vertreten sind
+func testRestoreSample(nodes []restic.FileNode, budget uint64) ([]string, bool) {
+	var files []restic.FileNode
+	var total uint64
+	for _, n := range nodes {
+		if n.Type == "file" {
+			files = append(files, n)
+			total += n.Size
+		}
 	}
-	out, err := a.runner.Run("", "", []string{"version"})
-	if err != nil {
-		return "?"
+	if total <= budget {
+		return nil, false
+	}
+	if budget == 0 {
+		return nil, true
 	}
-	return out
+	step := int(total/budget) + 1
+	var sample []string
+	var used uint64
+	for i := 0; i < len(files); i += step {
+		if used+files[i].Size > budget {
+			continue
+		}
+		used += files[i].Size
+		sample = append(sample, files[i].Path)
+	}
+	return sample, true
 }
 
-// ── Selective Restore ─────────────────────────────────────────────
+// RestoreVerification wird nach einer Wiederherstellung mit verify als
+// "restore:verify" gesendet
+type RestoreVerification struct {
+	Passed   bool        `json:"passed"`
+	Failures []FileError `json:"failures"`
+}
 
-// ListSnapshotContents lists all files in a snapshot via restic ls --json
-func (a *App) ListSnapshotContents(repoID, snapshotID string) ([]restic.FileNode, error) {
-	if a.runner == nil {
-		return nil, fmt.Errorf("restic not found")
+// FileError nennt eine Datei, die restic nicht sichern oder nicht korrekt
+// wiederherstellen konnte
+type FileError = history.FileError
+
+// runRestore runs restic restore for includePaths into target. With verify,
+// restic re-reads every restored file and compares its content hashes with
+// the snapshot (--verify). onLine receives stdout and stderr lines, since
+// restic reports files it could not restore on stderr.
+func (a *App) runRestore(repo config.Repository, snapshotID, target string, includePaths []string, verify bool, onLine func(string)) error {
+	if inlineArgsLen("include", includePaths) > maxInlineArgs {
+		// --include-file gibt es erst ab restic 0.17
+		if v, err := a.resticVersion(); err == nil && !v.Supports(restic.FeatureIncludeFile) {
+			return fmt.Errorf("too many paths selected for restic %s, select a parent folder or update restic to 0.17 or newer", v.Version)
+		}
 	}
-	repo, ok := a.config.GetRepository(repoID)
-	if !ok {
-		return nil, fmt.Errorf("repository not found")
+	args, cleanup, err := restoreArgs(snapshotID, target, includePaths, verify)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
+	// restic schreibt Dateifehler ("message_type":"error") nach stderr
+	return a.runner.RunWithProgress(resticRepo(repo).WithStderrLines(), args, onLine)
+}
 
-	args := []string{"ls", "--json", snapshotID}
-	out, err := a.runner.Run(repo.URI, repo.Password, args)
+// restoreArgs baut die Argumente für restic restore. Pfade werden als
+// "--flag=wert" übergeben und die Snapshot-ID steht hinter "--", damit
+// Pfade mit führendem "-" nicht als Flags interpretiert werden.
+// cleanup entfernt eine eventuell angelegte Include-Datei.
+func restoreArgs(snapshotID, target string, includePaths []string, verify bool) ([]string, func(), error) {
+	includeArgs, cleanup, err := patternArgs("include", includePaths)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	args := []string{"restore", "--target=" + target, "--json"}
+	if verify {
+		args = append(args, "--verify")
 	}
+	args = append(args, includeArgs...)
+	return append(args, "--", snapshotID), cleanup, nil
+}
 
-	var nodes []restic.FileNode
-	for _, line := range splitLines(out) {
-		if line == "" {
-			continue
-		}
-		var node restic.FileNode
-		if err := json.Unmarshal([]byte(line), &node); err != nil {
-			continue
-		}
-		// First line is snapshot info; file/dir nodes have struct_type "node"
-		if node.StructType == "node" {
-			nodes = append(nodes, node)
-		}
+// maxInlineArgs: Windows erlaubt 32767 Zeichen pro Kommandozeile. Der Rest
+// bleibt für restic-Pfad, Quellordner und die übrigen Flags.
+const maxInlineArgs = 24000
+
+// inlineArgsLen ist die Länge der Muster als einzelne "--flag=wert"-Argumente
+func inlineArgsLen(flag string, patterns []string) int {
+	n := 0
+	for _, p := range patterns {
+		n += len("--"+flag+"=") + len(p) + 3 // Leerzeichen und Anführungszeichen
 	}
-	return nodes, nil
+	return n
 }
 
-// RestoreSelected restores selected paths from a snapshot.
-// toOriginal=true  → temp dir on SAME drive → fast os.Rename to original path
-// toOriginal=false → restore directly to targetPath
-func (a *App) RestoreSelected(repoID, snapshotID string, includePaths []string, targetPath string, toOriginal bool) error {
-	if a.runner == nil {
-		return fmt.Errorf("restic not found")
+// patternArgs übergibt patterns als "--<flag>=…". Wird die Kommandozeile
+// dafür zu lang, landen sie stattdessen in einer temporären Datei, die per
+// "--<flag>-file=…" übergeben wird. cleanup löscht diese Datei wieder.
+func patternArgs(flag string, patterns []string) ([]string, func(), error) {
+	if inlineArgsLen(flag, patterns) <= maxInlineArgs {
+		args := make([]string, 0, len(patterns))
+		for _, p := range patterns {
+			args = append(args, "--"+flag+"="+p)
+		}
+		return args, func() {}, nil
 	}
-	repo, ok := a.config.GetRepository(repoID)
-	if !ok {
-		return fmt.Errorf("repository not found")
+	f, err := os.CreateTemp("", "restic-gui-"+flag+"-*.txt")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create %s file: %w", flag, err)
 	}
-	if len(includePaths) == 0 {
-		return fmt.Errorf("no paths selected")
+	name := f.Name()
+	cleanup := func() { os.Remove(name) }
+	_, err = f.WriteString(strings.Join(patterns, "\n") + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
 	}
-
-	if toOriginal {
-		// Restic stores Windows paths as /G/folder (drive letter = first dir).
-		// Strategy: restore to temp on SAME drive → os.Rename (no copy needed).
-		go func() {
-			// Extract drive letter from first include path: e.g. "/G/..." → "G"
-			driveLetter := extractDriveLetter(includePaths[0])
-			if driveLetter == "" {
-				runtime.EventsEmit(a.ctx, "restore:error", "Could not determine drive letter from path")
-				return
-			}
-
-			// Create temp dir on SAME drive: e.g. G:\restic-gui-temp-<uuid>
-			tempDir := driveLetter + `:\restic-gui-temp-` + uuid.New().String()[:8]
-			if err := os.MkdirAll(tempDir, 0755); err != nil {
-				runtime.EventsEmit(a.ctx, "restore:error", "Failed to create temp directory: "+err.Error())
-				return
-			}
-			defer os.RemoveAll(tempDir)
-
-			// Restore in Temp: Ergebnis z.B. tempDir\G\namDHC_v113
-			args := []string{"restore", snapshotID, "--target", tempDir, "--json"}
-			for _, p := range includePaths {
-				args = append(args, "--include", p)
-			}
-			err := a.runner.RunWithProgress(repo.URI, repo.Password, args, func(line string) {
-				var progress restic.RestoreProgress
-				if jsonErr := json.Unmarshal([]byte(line), &progress); jsonErr == nil {
-					runtime.EventsEmit(a.ctx, "restore:progress", progress)
-				}
-			})
-			if err != nil {
-				runtime.EventsEmit(a.ctx, "restore:error", err.Error())
-				return
-			}
-
-			// tempDir\G\* → G:\*  (fast rename on same drive)
-			srcBase := filepath.Join(tempDir, driveLetter)
-			dstBase := driveLetter + `:\`
-			if err := moveContents(srcBase, dstBase); err != nil {
-				runtime.EventsEmit(a.ctx, "restore:error", "Move failed: "+err.Error())
-				return
-			}
-			runtime.EventsEmit(a.ctx, "restore:complete", nil)
-		}()
-		return nil
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write %s file: %w", flag, err)
 	}
+	return []string{"--" + flag + "-file=" + name}, cleanup, nil
+}
 
-	// ── Custom Target Restore ─────────────────────────────────────────────────
-	args := []string{"restore", snapshotID, "--target", targetPath, "--json"}
-	for _, p := range includePaths {
-		args = append(args, "--include", p)
+// underAny prüft, ob path einer der prefixes ist oder darunter liegt.
+// Ohne prefixes trifft jeder Pfad zu.
+func underAny(path string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
 	}
-	go func() {
-		err := a.runner.RunWithProgress(repo.URI, repo.Password, args, func(line string) {
-			var progress restic.RestoreProgress
-			if jsonErr := json.Unmarshal([]byte(line), &progress); jsonErr == nil {
-				runtime.EventsEmit(a.ctx, "restore:progress", progress)
-			}
-		})
-		if err != nil {
-			runtime.EventsEmit(a.ctx, "restore:error", err.Error())
-		} else {
-			runtime.EventsEmit(a.ctx, "restore:complete", nil)
+	for _, p := range prefixes {
+		p = strings.TrimSuffix(p, "/")
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
 		}
-	}()
-	return nil
+	}
+	return false
+}
+
+// localPath wandelt einen restic-Pfad in den Originalpfad auf der Festplatte um.
+// Unter Windows wird "/G/folder/file" zu "G:\folder\file".
+func localPath(resticPath string) string {
+	if goruntime.GOOS != "windows" {
+		return resticPath
+	}
+	drive := extractDriveLetter(resticPath)
+	if drive == "" {
+		return filepath.FromSlash(resticPath)
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(filepath.ToSlash(resticPath), "/"), drive)
+	rest = strings.TrimPrefix(strings.TrimPrefix(rest, strings.ToLower(drive)), "/")
+	return drive + `:\` + filepath.FromSlash(rest)
 }
 
 // extractDriveLetter liest den Laufwerksbuchstaben aus einem restic-Pfad.
@@ -379,9 +4226,18 @@ func extractDriveLetter(path string) string {
 	return ""
 }
 
+// CopyProgress wird während des Kopier-Fallbacks als "restore:copying" gesendet
+type CopyProgress struct {
+	CurrentPath string  `json:"current_path"`
+	BytesCopied int64   `json:"bytes_copied"`
+	TotalBytes  int64   `json:"total_bytes"`
+	PercentDone float64 `json:"percent_done"`
+}
+
 // moveContents verschiebt alle Einträge aus src direkt nach dst.
 // Da src und dst auf dem gleichen Laufwerk liegen, ist os.Rename instant.
-func moveContents(src, dst string) error {
+// onCopy wird nur aufgerufen, wenn auf Kopieren zurückgefallen werden muss.
+func moveContents(src, dst string, onCopy func(CopyProgress)) error {
 	entries, err := os.ReadDir(src)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -390,40 +4246,115 @@ func moveContents(src, dst string) error {
 		return err
 	}
 	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-		// Ziel-Eltern sicherstellen
-		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		if err := moveEntry(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), onCopy); err != nil {
 			return err
 		}
-		// Rename: auf gleichem Laufwerk = sofortiger Vorgang
-		if err := os.Rename(srcPath, dstPath); err != nil {
-			// Fallback: kopieren + löschen (anderes Laufwerk)
-			if copyErr := copyPath(srcPath, dstPath); copyErr != nil {
-				return copyErr
-			}
-			os.RemoveAll(srcPath)
+	}
+	return nil
+}
+
+// moveEntry verschiebt eine Datei oder einen Ordner von srcPath nach dstPath.
+func moveEntry(srcPath, dstPath string, onCopy func(CopyProgress)) error {
+	// Ziel-Eltern sicherstellen
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return err
+	}
+	// Rename: auf gleichem Laufwerk = sofortiger Vorgang
+	if err := os.Rename(srcPath, dstPath); err == nil {
+		return nil
+	}
+	// Fallback: kopieren + löschen (anderes Laufwerk)
+	total := pathSize(srcPath)
+	lastPercent := -1
+	progress := func(copied int64) {
+		p := CopyProgress{CurrentPath: dstPath, BytesCopied: copied, TotalBytes: total, PercentDone: 1}
+		if total > 0 {
+			p.PercentDone = float64(copied) / float64(total)
 		}
+		// Nur bei Prozentsprung senden, sonst flutet ein großer Kopiervorgang die IPC
+		if percent := int(p.PercentDone * 100); percent != lastPercent && onCopy != nil {
+			lastPercent = percent
+			onCopy(p)
+		}
+	}
+	var copied int64
+	if err := copyPath(srcPath, dstPath, func(n int64) {
+		copied += n
+		progress(copied)
+	}); err != nil {
+		return err
 	}
+	os.RemoveAll(srcPath)
 	return nil
 }
 
+// topLevelPaths entfernt Pfade, deren Elternordner ebenfalls ausgewählt ist.
+func topLevelPaths(paths []string) []string {
+	var top []string
+	for _, p := range paths {
+		covered := false
+		for _, other := range paths {
+			if other != p && underAny(p, []string{other}) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			top = append(top, p)
+		}
+	}
+	return top
+}
+
+// pathSize summiert die Dateigrößen unterhalb von path.
+func pathSize(path string) int64 {
+	var total int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
 // copyPath kopiert eine Datei oder einen Ordner rekursiv.
-func copyPath(src, dst string) error {
-	info, err := os.Stat(src)
+// Symlinks werden als Symlinks neu angelegt, nicht ihr Ziel kopiert.
+// Unter Unix wird der Eigentümer übernommen, sofern die App die Rechte dazu hat.
+// onWrite erhält die Anzahl der jeweils neu geschriebenen Bytes.
+func copyPath(src, dst string, onWrite func(int64)) error {
+	info, err := os.Lstat(src)
 	if err != nil {
 		return err
 	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		os.Remove(dst)
+		if err := os.Symlink(target, dst); err != nil {
+			return err
+		}
+		copyOwner(info, dst)
+		return nil
+	}
+	if !info.Mode().IsRegular() && !info.IsDir() {
+		// Gerätedateien, FIFOs und Sockets lassen sich nicht per Kopie
+		// nachbilden – überspringen statt blockierend daraus zu lesen
+		return nil
+	}
 	if info.IsDir() {
 		if err := os.MkdirAll(dst, info.Mode()); err != nil {
 			return err
 		}
 		entries, _ := os.ReadDir(src)
 		for _, e := range entries {
-			if err := copyPath(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			if err := copyPath(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name()), onWrite); err != nil {
 				return err
 			}
 		}
+		copyOwner(info, dst)
 		return nil
 	}
 	// Datei kopieren
@@ -437,21 +4368,38 @@ func copyPath(src, dst string) error {
 		return err
 	}
 	defer out.Close()
-	_, err = io.Copy(out, in)
-	return err
+	if _, err := io.CopyBuffer(&progressWriter{w: out, onWrite: onWrite}, in, make([]byte, 1<<20)); err != nil {
+		return err
+	}
+	copyOwner(info, dst)
+	return nil
 }
 
-func splitLines(s string) []string {
-	var lines []string
-	start := 0
-	for i, c := range s {
-		if c == '\n' {
-			lines = append(lines, s[start:i])
-			start = i + 1
-		}
+// progressWriter meldet jeden Schreibvorgang an onWrite weiter.
+type progressWriter struct {
+	w       io.Writer
+	onWrite func(int64)
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	if pw.onWrite != nil && n > 0 {
+		pw.onWrite(int64(n))
 	}
-	if start < len(s) {
-		lines = append(lines, s[start:])
+	return n, err
+}
+
+// mergeUnique hängt b an a an und entfernt leere Einträge und Duplikate.
+func mergeUnique(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var merged []string
+	for _, v := range append(append([]string{}, a...), b...) {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		merged = append(merged, v)
 	}
-	return lines
+	return merged
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"restic-gui/internal/config"
+	"restic-gui/internal/history"
+	"restic-gui/internal/i18n"
+	"restic-gui/internal/jobs"
+	"restic-gui/internal/restic"
+)
+
+// cliProgressInterval: so oft schreibt --run-backup eine Fortschrittszeile
+const cliProgressInterval = 5 * time.Second
+
+// exitCancelled ist der übliche Exit-Code nach Strg+C
+const exitCancelled = 130
+
+// runHeadless handles command-line invocations that run without the GUI,
+// e.g. from Task Scheduler or cron:
+//
+//	ResticBackupManager --run-backup <repository name or ID> [--skip-missing] [--tag-by-folder]
+//
+// ok is false when args contain no headless command, then the GUI starts.
+// On Windows the release build has no console, so redirect the output to a
+// file to keep it.
+func runHeadless(args []string) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	name, value, _ := strings.Cut(args[0], "=")
+	if name != "--run-backup" {
+		return 0, false
+	}
+	rest := args[1:]
+	if value == "" && len(rest) > 0 && !strings.HasPrefix(rest[0], "--") {
+		value, rest = rest[0], rest[1:]
+	}
+	if value == "" {
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return 2, true
+	}
+	var opts restic.BackupJob
+	for _, arg := range rest {
+		switch arg {
+		case "--skip-missing":
+			opts.SkipMissing = true
+		case "--tag-by-folder":
+			opts.TagByFolder = true
+		default:
+			fmt.Fprintf(os.Stderr, "unknown option %q\n%s\n", arg, cliUsage)
+			return 2, true
+		}
+	}
+	return runBackupCLI(value, opts), true
+}
+
+const cliUsage = "usage: --run-backup <repository name or ID> [--skip-missing] [--tag-by-folder]"
+
+// runBackupCLI runs a configured repository's backup with its sources,
+// excludes, tags, hooks, size limit and notifications. It goes through the
+// same queueBackup as the GUI; events are printed by console instead.
+// The result is restic's exit code.
+func runBackupCLI(nameOrID string, opts restic.BackupJob) int {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Config error:", err)
+		return 1
+	}
+	for _, w := range cm.Warnings() {
+		fmt.Fprintln(os.Stderr, "Config:", w)
+	}
+	i18n.SetLanguage(cm.GetLanguage())
+	if err := restic.LoadErrorRules(filepath.Join(cm.Dir(), "error-rules.json")); err != nil {
+		fmt.Fprintln(os.Stderr, "Custom error rules ignored:", err)
+	}
+
+	repo, err := findRepository(cm, nameOrID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	runner, err := restic.NewRunner()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	runner.SetMaxOutput(cm.MaxOutputBytes())
+
+	finished := make(chan struct{})
+	a := &App{
+		ctx:     context.Background(),
+		config:  cm,
+		runner:  runner,
+		console: &console{},
+		backups: history.NewLog[history.BackupEntry](cm.Dir(), "backup-history.json"),
+		jobs: jobs.NewQueue(1, func(event string, _ jobs.Info) {
+			if event == "job:finished" {
+				close(finished)
+			}
+		}),
+	}
+
+	if cm.GetSafeMode() {
+		fmt.Println("Safe mode is on: dry run, nothing will be stored")
+	}
+	fmt.Printf("Backing up %s to %s\n", strings.Join(repo.SourceFolders, ", "), repo.Name)
+	job := opts
+	job.RepoID = repo.ID
+	job.SourcePaths = repo.SourceFolders
+	job.Excludes = repo.Excludes
+	var outcome backupOutcome
+	err = restic.ErrCancelled // gilt, falls der Job vor dem Start abgebrochen wird
+	if _, qErr := a.queueBackup(job, func(o backupOutcome, e error) { outcome, err = o, e }); qErr != nil {
+		fmt.Fprintln(os.Stderr, qErr)
+		return 1
+	}
+
+	interrupt, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	select {
+	case <-finished:
+	case <-interrupt.Done():
+		a.CancelBackup()
+		<-finished
+	}
+	// Benachrichtigungen laufen im Hintergrund und sollen noch rausgehen
+	a.ops.Wait()
+
+	if s := backupSummary(outcome.Summary); s != "" {
+		fmt.Println(s)
+	}
+	if outcome.ErrorCount > 0 {
+		fmt.Fprintf(os.Stderr, "%d file(s) could not be backed up\n", outcome.ErrorCount)
+	}
+	var cmdErr *restic.CommandError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, restic.ErrCancelled):
+		fmt.Fprintln(os.Stderr, "Backup cancelled")
+		return exitCancelled
+	case errors.As(err, &cmdErr):
+		fmt.Fprintln(os.Stderr, "Backup failed:", cmdErr.Message)
+		return cmdErr.ExitCode
+	default:
+		fmt.Fprintln(os.Stderr, "Backup failed:", err)
+		return 1
+	}
+}
+
+// findRepository sucht ein Repository nach ID oder eindeutigem Namen
+func findRepository(cm *config.ConfigManager, nameOrID string) (config.Repository, error) {
+	if repo, ok := cm.GetRepository(nameOrID); ok {
+		return repo, nil
+	}
+	var found []config.Repository
+	for _, r := range cm.GetRepositories() {
+		if strings.EqualFold(r.Name, nameOrID) {
+			found = append(found, r)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return config.Repository{}, fmt.Errorf("repository %q not found", nameOrID)
+	case 1:
+		return found[0], nil
+	default:
+		return config.Repository{}, fmt.Errorf("%d repositories are named %q; use the ID instead", len(found), nameOrID)
+	}
+}
+
+// console gibt ohne GUI die Events des Backup-Ablaufs aus, die in ein
+// Protokoll gehören: Status höchstens alle cliProgressInterval, Warnungen
+// und Dateifehler sofort
+type console struct {
+	mu        sync.Mutex
+	lastPrint time.Time
+}
+
+func (c *console) event(name string, data interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch v := data.(type) {
+	case restic.BackupStatus:
+		if name != "backup:progress" || time.Since(c.lastPrint) < cliProgressInterval {
+			return
+		}
+		c.lastPrint = time.Now()
+		fmt.Printf("%5.1f%%  %d/%d files  %d/%d bytes\n", v.PercentDone*100,
+			v.FilesDone, v.TotalFiles, v.BytesDone, v.TotalBytes)
+	case FileError:
+		fmt.Fprintf(os.Stderr, "error: %s: %s\n", v.Path, v.Message)
+	case BackupHook:
+		// Fehler samt Ausgabe meldet runHook bereits über logWarning
+		if v.Error == "" && v.Output != "" {
+			fmt.Printf("[%s] %s\n", v.Stage, v.Output)
+		}
+	case BackupRetry:
+		fmt.Fprintf(os.Stderr, "Network error, retrying in %ds: %s\n", v.DelaySeconds, v.Error)
+	case QuotaWarning:
+		fmt.Fprintf(os.Stderr, "Size limit: the repository would grow to %d bytes, the limit is %d bytes\n", v.ProjectedSize, v.MaxSize)
+	case string:
+		if name == "backup:warning" {
+			fmt.Fprintln(os.Stderr, v)
+		}
+	}
+}
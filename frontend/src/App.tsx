@@ -6,7 +6,12 @@ import Backup from './pages/Backup';
 import Snapshots from './pages/Snapshots';
 import Restore from './pages/Restore';
 import SelectiveRestore from './pages/SelectiveRestore';
-import { GetResticVersion, GetResticStatus } from '../wailsjs/go/main/App';
+import {
+    GetResticVersion, GetResticStatus, GetUIState, SaveUIState, ListProfiles, GetActiveProfile, SwitchProfile,
+    CheckResticUpdate, GetCheckResticUpdates, SetCheckResticUpdates, UpdateRestic, CancelAll, GetConfigLocation,
+    GetSafeMode, SetSafeMode
+} from '../wailsjs/go/main/App';
+import { EventsOn, EventsOff } from '../wailsjs/runtime/runtime';
 
 type Page = 'repos' | 'backup' | 'snapshots' | 'restore' | 'selective';
 
@@ -32,9 +37,81 @@ export default function App() {
     const [resticVersion, setResticVersion] = useState('');
     const [resticMissing, setResticMissing] = useState(false);
     const [resticMsg, setResticMsg] = useState('');
+    const [resticLatest, setResticLatest] = useState('');
+    const [uiLoaded, setUiLoaded] = useState(false);
+    const [profiles, setProfiles] = useState<string[]>([]);
+    const [profile, setProfile] = useState('');
+    const [configWarnings, setConfigWarnings] = useState<string[]>([]);
+    const [safeMode, setSafeModeState] = useState(false);
 
     useEffect(() => {
+        GetSafeMode().then(setSafeModeState).catch(() => { });
+        // EventsOn liefert die Abmeldung nur für diesen Listener; andere Seiten hören auch zu
+        return EventsOn('safemode:changed', setSafeModeState);
+    }, []);
+
+    const toggleSafeMode = async () => {
+        const next = !safeMode;
+        if (!next && !confirm('Turn off safe mode?\nBackups will be stored and snapshots can be deleted again.')) return;
+        try {
+            await SetSafeMode(next);
+        } catch (e: unknown) { alert(String(e)); }
+    };
+
+    const loadProfiles = () => {
+        ListProfiles().then((p: string[]) => setProfiles(p || [])).catch(() => { });
+        GetActiveProfile().then(setProfile).catch(() => { });
+    };
+    useEffect(loadProfiles, []);
+
+    const changeProfile = async (value: string) => {
+        let name = value;
+        if (value === '__new') {
+            name = (prompt('Name of the new profile (letters, digits, - and _):') || '').trim();
+            if (!name) return;
+        }
+        try { await SwitchProfile(name); } catch (e: unknown) { alert(String(e)); }
+        loadProfiles();
+    };
+
+    const checkUpdate = () => CheckResticUpdate().then((u: { latest: string; available: boolean }) =>
+        setResticLatest(u.available ? u.latest : '')).catch(() => { });
+
+    const toggleUpdateCheck = async () => {
+        const enabled = await GetCheckResticUpdates();
+        if (!confirm(enabled ? 'Stop checking GitHub for new restic releases?' : 'Check GitHub for new restic releases on start?')) return;
+        await SetCheckResticUpdates(!enabled);
+        if (enabled) setResticLatest(''); else checkUpdate();
+    };
+
+    const updateRestic = async () => {
+        if (!confirm(`Update restic to ${resticLatest}?`)) return;
+        try {
+            const v: { version: string } = await UpdateRestic();
+            GetResticVersion().then((s: string) => setResticVersion(s.trim())).catch(() => { });
+            setResticLatest('');
+            alert(`restic ${v.version} installed.`);
+        } catch (e: unknown) { alert(String(e)); }
+    };
+
+    const stopEverything = () => {
+        if (!confirm('Stop all running backups, restores, checks and other restic commands?')) return;
+        CancelAll();
+    };
+
+    useEffect(() => {
+        EventsOn('all:cancelled', (stopped: boolean) =>
+            alert(stopped ? 'All operations were stopped.' : 'Some operations are still shutting down.'));
+        return () => EventsOff('all:cancelled');
+    }, []);
+
+    useEffect(() => {
+        GetUIState().then((s: { lastTab: string }) => {
+            if (s.lastTab && s.lastTab in pageTitles) setPage(s.lastTab as Page);
+        }).catch(() => { }).finally(() => setUiLoaded(true));
         GetResticVersion().then((v: string) => setResticVersion(v.trim())).catch(() => { });
+        GetConfigLocation().then((c: { warnings: string[] | null }) => setConfigWarnings(c.warnings || [])).catch(() => { });
+        checkUpdate();
         GetResticStatus().then((s: Record<string, string>) => {
             if (s.found === 'false') {
                 setResticMissing(true);
@@ -45,6 +122,11 @@ export default function App() {
         }).catch(() => { });
     }, []);
 
+    useEffect(() => {
+        if (!uiLoaded) return;
+        GetUIState().then((s: Record<string, unknown>) => SaveUIState({ ...s, lastTab: page })).catch(() => { });
+    }, [page, uiLoaded]);
+
     const goToRestore = (repoId: string, snapshotId: string) => {
         setRestoreParams({ repoId, snapshotId });
         setPage('selective');
@@ -76,12 +158,40 @@ export default function App() {
                     </nav>
 
                     <div className="sidebar-footer">
+                        <button className="btn btn-danger btn-sm" style={{ width: '100%', marginBottom: 10 }} onClick={stopEverything}
+                            title="Cancel every running operation">
+                            ⏹ Stop everything
+                        </button>
+                        <label style={{ display: 'flex', alignItems: 'center', gap: 6, fontSize: 12, marginBottom: 10, cursor: 'pointer' }}
+                            title="Backups only run as dry run, nothing can be deleted or overwritten">
+                            <input type="checkbox" checked={safeMode} onChange={toggleSafeMode} />
+                            🛡 Safe mode
+                        </label>
+                        <select value={profile} onChange={e => changeProfile(e.target.value)}
+                            style={{ width: '100%', marginBottom: 10, fontSize: 12 }} title="Configuration profile">
+                            {profiles.map(p => <option key={p} value={p}>👤 {p}</option>)}
+                            <option value="__new">+ New profile…</option>
+                        </select>
                         <div style={{ fontWeight: 600, color: resticMissing ? 'var(--danger)' : 'var(--text-1)', marginBottom: 4, fontSize: 12 }}>
                             Restic Backup Manager v0.9.1
                         </div>
-                        <div style={{ fontSize: 11, opacity: 0.6 }}>
+                        <div style={{ fontSize: 11, opacity: 0.6, cursor: 'pointer' }} onClick={toggleUpdateCheck}
+                            title="Click to toggle the restic update check">
                             {resticMissing ? '⚠ restic not found' : (resticVersion ? resticVersion.split('\n')[0] : '...')}
                         </div>
+                        {configWarnings.length > 0 && (
+                            <div style={{ fontSize: 11, color: 'var(--warning)', marginTop: 4, cursor: 'pointer' }}
+                                title="Problems with the configuration directory – click for details" onClick={() => alert(configWarnings.join('\n'))}>
+                                ⚠ Settings storage
+                            </div>
+                        )}
+                        {resticLatest && (
+                            <div style={{ fontSize: 11, color: 'var(--warning)', marginTop: 4, cursor: 'pointer' }}
+                                title={safeMode ? 'A newer restic release is available – turn off safe mode to update' : 'A newer restic release is available – click to update'}
+                                onClick={safeMode ? undefined : updateRestic}>
+                                ⬆ restic {resticLatest} available
+                            </div>
+                        )}
                     </div>
                 </aside>
 
@@ -89,6 +199,7 @@ export default function App() {
                 <div className="main">
                     <div className="topbar">
                         <div className="topbar-title">{pageTitles[page]}</div>
+                        {safeMode && <span className="badge badge-info" title="Backups, forget and prune are dry runs; deleting and overwriting is disabled">🛡 Safe mode</span>}
                     </div>
 
                     <div className="content">
@@ -123,7 +234,8 @@ export default function App() {
                                 </div>
                             </div>
                         ) : (
-                            <>
+                            // key: nach einem Profilwechsel laden alle Seiten neu
+                            <React.Fragment key={profile}>
                                 {page === 'repos' && <Repositories />}
                                 {page === 'backup' && <Backup />}
                                 {page === 'snapshots' && <Snapshots onRestore={goToRestore} />}
@@ -139,7 +251,7 @@ export default function App() {
                                         initSnapshotId={restoreParams?.snapshotId}
                                     />
                                 )}
-                            </>
+                            </React.Fragment>
                         )}
                     </div>
                 </div>
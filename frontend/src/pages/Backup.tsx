@@ -2,7 +2,8 @@ import React, { useState, useEffect, useCallback } from 'react';
 import { useToast } from '../ToastContext';
 import { EventsOn, EventsOff } from '../../wailsjs/runtime/runtime';
 import {
-    GetRepositories, StartBackup, CancelBackup, SelectFolders, InitRepository, UpdateRepository
+    GetRepositories, GetRecentRepositories, StartBackup, CancelBackup, SelectFolders, InitRepository, UpdateRepository,
+    GetExcludeAppDirs, SetExcludeAppDirs, CheckExcludes, GetSafeMode
 } from '../../wailsjs/go/main/App';
 
 interface Repo { id: string; name: string; uri: string; password: string; sourceFolders: string[]; excludes: string[]; }
@@ -13,13 +14,17 @@ interface Progress {
     files_done: number;
     total_bytes: number;
     bytes_done: number;
-    current_files: string[];
+    current_file?: string;
+    current_sources?: string[];
     seconds_elapsed: number;
     seconds_remaining: number;
     files_new: number;
     files_changed: number;
     data_added: number;
     snapshot_id: string;
+    dry_run?: boolean;
+    bytes_per_second?: number;
+    files_per_second?: number;
 }
 
 function fmt(bytes: number): string {
@@ -46,16 +51,30 @@ export default function Backup() {
     const [paths, setPaths] = useState<string[]>([]);
     const [excludes, setExcludes] = useState<string[]>(['node_modules', '.git', '__pycache__']);
     const [excludeInput, setExcludeInput] = useState('');
+    const [excludeAppDirs, setExcludeAppDirs] = useState(true);
+    const [tagByFolder, setTagByFolder] = useState(false);
     const [status, setStatus] = useState<'idle' | 'running' | 'done' | 'error'>('idle');
     const [progress, setProgress] = useState<Progress | null>(null);
     const [summary, setSummary] = useState<Progress | null>(null);
     const [errMsg, setErrMsg] = useState('');
     const [initializing, setInitializing] = useState(false);
+    const [recent, setRecent] = useState<Repo[]>([]);
+    const [safeMode, setSafeMode] = useState(false);
+    const [fileErrors, setFileErrors] = useState<{ count: number; files: { path: string; message: string }[] }>({ count: 0, files: [] });
+
+    useEffect(() => {
+        GetSafeMode().then(setSafeMode).catch(() => { });
+        return EventsOn('safemode:changed', setSafeMode);
+    }, []);
 
     useEffect(() => {
         GetRepositories().then((r: Repo[]) => {
             setRepos(r || []);
-            if (r?.length > 0) setSelectedRepo(r[0].id);
+            GetRecentRepositories().then((rr: Repo[]) => {
+                setRecent(rr || []);
+                if (rr?.length > 0) setSelectedRepo(rr[0].id);
+                else if (r?.length > 0) setSelectedRepo(r[0].id);
+            }).catch(() => { if (r?.length > 0) setSelectedRepo(r[0].id); });
         });
     }, []);
 
@@ -67,14 +86,33 @@ export default function Backup() {
         }
     }, [selectedRepo, repos]);
 
+    useEffect(() => { GetExcludeAppDirs().then(setExcludeAppDirs).catch(() => { }); }, []);
+
     useEffect(() => {
-        EventsOn('backup:progress', (p: Progress) => {
-            if (p.message_type === 'status') setProgress(p);
-            else if (p.message_type === 'summary') setSummary(p);
+        EventsOn('backup:warning', (msg: string) => addToast({ type: 'warning', title: 'Sensitive folder in source', message: msg }));
+        EventsOn('backup:progress', (p: Progress) => setProgress(p));
+        EventsOn('backup:summary', (p: Progress) => setSummary(p));
+        EventsOn('backup:fileerror', (e: { path: string; message: string }) =>
+            addToast({ type: 'warning', title: 'File skipped', message: e.path ? `${e.path}: ${e.message}` : e.message }));
+        EventsOn('backup:quotawarning', (q: { repoName: string; projectedSize: number; maxSize: number; refused: boolean }) =>
+            addToast({
+                type: q.refused ? 'error' : 'warning',
+                title: q.refused ? 'Backup refused: size limit' : 'Size limit will be exceeded',
+                message: `${q.repoName}: about ${(q.projectedSize / 1e9).toFixed(1)} GB of ${(q.maxSize / 1e9).toFixed(1)} GB allowed`,
+            }));
+        EventsOn('backup:retry', (r: { attempt: number; maxRetries: number; delaySeconds: number; error: string }) => {
+            addToast({ type: 'warning', title: `Retrying backup (${r.attempt}/${r.maxRetries}) in ${r.delaySeconds}s`, message: r.error });
+        });
+        EventsOn('backup:complete', (c: { errorCount: number; fileErrors: { path: string; message: string }[] | null }) => {
+            setFileErrors({ count: c?.errorCount || 0, files: c?.fileErrors || [] });
+            setStatus('done');
         });
-        EventsOn('backup:complete', () => { setStatus('done'); });
         EventsOn('backup:error', (msg: string) => { setStatus('error'); setErrMsg(msg); });
-        return () => { EventsOff('backup:progress'); EventsOff('backup:complete'); EventsOff('backup:error'); };
+        return () => {
+            EventsOff('backup:warning'); EventsOff('backup:progress'); EventsOff('backup:summary');
+            EventsOff('backup:fileerror'); EventsOff('backup:retry'); EventsOff('backup:quotawarning');
+            EventsOff('backup:complete'); EventsOff('backup:error');
+        };
     }, []);
 
     const updateRepoConfig = (newPaths: string[], newExcludes: string[]) => {
@@ -101,8 +139,13 @@ export default function Backup() {
         } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
     };
 
-    const addExclude = () => {
-        const v = excludeInput.trim();
+    const addExclude = async () => {
+        let v = excludeInput.trim();
+        const issues: { normalized: string; reasons: string[] }[] = await CheckExcludes([v]).catch(() => []) || [];
+        if (issues.length > 0 && issues[0].normalized && issues[0].normalized !== v
+            && confirm(`"${v}" will probably not match (${issues[0].reasons.join(', ')}).\n\nUse "${issues[0].normalized}" instead?`)) {
+            v = issues[0].normalized;
+        }
         if (v && !excludes.includes(v)) {
             const next = [...excludes, v];
             setExcludes(next);
@@ -128,7 +171,7 @@ export default function Backup() {
         if (paths.length === 0) { addToast({ type: 'warning', title: 'No source folders selected' }); return; }
         setStatus('running'); setProgress(null); setSummary(null); setErrMsg('');
         try {
-            await StartBackup({ repoId: selectedRepo, sourcePaths: paths, excludes, tags: [] });
+            await StartBackup({ repoId: selectedRepo, sourcePaths: paths, excludes, tags: [], tagByFolder });
         } catch (e: unknown) { setStatus('error'); setErrMsg(String(e)); }
     };
 
@@ -138,7 +181,7 @@ export default function Backup() {
         addToast({ type: 'info', title: 'Backup cancelled' });
     };
 
-    const reset = () => { setStatus('idle'); setProgress(null); setSummary(null); };
+    const reset = () => { setStatus('idle'); setProgress(null); setSummary(null); setFileErrors({ count: 0, files: [] }); };
     const pct = progress ? Math.round(progress.percent_done * 100) : 0;
 
     return (
@@ -158,6 +201,14 @@ export default function Backup() {
                     : <select value={selectedRepo} onChange={e => setSelectedRepo(e.target.value)} disabled={status === 'running'}>
                         {repos.map(r => <option key={r.id} value={r.id}>{r.name} — {r.uri}</option>)}
                     </select>}
+                {recent.length > 1 && (
+                    <div style={{ display: 'flex', gap: 6, marginTop: 8, flexWrap: 'wrap' }}>
+                        {recent.map(r => (
+                            <button key={r.id} className="btn btn-ghost btn-sm" disabled={status === 'running' || r.id === selectedRepo}
+                                onClick={() => setSelectedRepo(r.id)}>🕘 {r.name}</button>
+                        ))}
+                    </div>
+                )}
             </div>
 
             {/* Source paths */}
@@ -199,6 +250,16 @@ export default function Backup() {
                         </span>
                     ))}
                 </div>
+                <label style={{ display: 'flex', alignItems: 'center', gap: 8, marginTop: 10, fontSize: 13, color: 'var(--text-2)' }}>
+                    <input type="checkbox" checked={excludeAppDirs} disabled={status === 'running'}
+                        onChange={e => { const v = e.target.checked; setExcludeAppDirs(v); SetExcludeAppDirs(v).catch(() => { }); }} />
+                    Always exclude the restic cache and this app's configuration
+                </label>
+                <label style={{ display: 'flex', alignItems: 'center', gap: 8, marginTop: 6, fontSize: 13, color: 'var(--text-2)' }}>
+                    <input type="checkbox" checked={tagByFolder} disabled={status === 'running'}
+                        onChange={e => setTagByFolder(e.target.checked)} />
+                    Tag the snapshot with the name of each source folder
+                </label>
             </div>
 
             {status === 'idle' && (
@@ -238,8 +299,11 @@ export default function Backup() {
                             </div>
                         </div>
                     )}
+                    {paths.length > 1 && progress?.current_sources && progress.current_sources.length > 0 && (
+                        <div className="current-file">📁 {progress.current_sources.join(', ')}</div>
+                    )}
                     <div className="current-file">
-                        📄 {progress?.current_files?.[0] || '...'}
+                        📄 {progress?.current_file || '...'}
                     </div>
                 </div>
             )}
@@ -247,7 +311,7 @@ export default function Backup() {
             {status === 'done' && summary && (
                 <div className="status-card" style={{ borderColor: 'var(--success)' }}>
                     <div style={{ color: 'var(--success)', fontWeight: 700, fontSize: 16, marginBottom: 16 }}>
-                        ✅ Backup successful!
+                        {summary.dry_run || safeMode ? '🛡 Dry run finished (safe mode) – nothing was stored' : '✅ Backup successful!'}
                     </div>
                     <div className="progress-stats">
                         <div className="stat-item">
@@ -262,11 +326,32 @@ export default function Backup() {
                             <div className="stat-val">{fmt(summary.data_added)}</div>
                             <div className="stat-label">Data added</div>
                         </div>
+                        <div className="stat-item">
+                            <div className="stat-val">{summary.bytes_per_second ? fmt(summary.bytes_per_second) + '/s' : '–'}</div>
+                            <div className="stat-label">
+                                Throughput{summary.files_per_second ? ` · ${summary.files_per_second.toFixed(1)} files/s` : ''}
+                            </div>
+                        </div>
                         <div className="stat-item">
                             <div className="stat-val" style={{ fontSize: 13 }}>{summary.snapshot_id}</div>
                             <div className="stat-label">Snapshot ID</div>
                         </div>
                     </div>
+                    {fileErrors.count > 0 && (
+                        <details style={{ marginTop: 16, fontSize: 12 }}>
+                            <summary style={{ color: 'var(--warning)', cursor: 'pointer' }}>
+                                ⚠ {fileErrors.count} file(s) could not be backed up
+                                {fileErrors.count > fileErrors.files.length && ` (first ${fileErrors.files.length} shown)`}
+                            </summary>
+                            <div style={{ maxHeight: 200, overflowY: 'auto', marginTop: 8 }}>
+                                {fileErrors.files.map(e => (
+                                    <div key={e.path} title={e.message}>
+                                        <code>{e.path}</code> <span style={{ color: 'var(--text-3)' }}>— {e.message}</span>
+                                    </div>
+                                ))}
+                            </div>
+                        </details>
+                    )}
                     <div style={{ marginTop: 16 }}>
                         <button className="btn btn-secondary" onClick={reset}>↩ New Backup</button>
                     </div>
@@ -295,7 +380,7 @@ export default function Backup() {
                                     onClick={async () => {
                                         setInitializing(true);
                                         try {
-                                            await InitRepository(repo);
+                                            await InitRepository(repo, '');
                                             addToast({ type: 'success', title: '✅ Repository initialized!', message: 'You can now start the backup.' });
                                             reset();
                                         } catch (e: unknown) {
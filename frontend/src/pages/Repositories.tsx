@@ -1,12 +1,34 @@
 import React, { useState, useEffect } from 'react';
 import { useToast } from '../ToastContext';
+import { EventsOn, EventsOff } from '../../wailsjs/runtime/runtime';
 import {
     GetRepositories, AddRepository, UpdateRepository,
-    DeleteRepository, TestRepository, InitRepository
+    DeleteRepository, DuplicateRepository, RenameRepository, TestRepository, InitRepository, CheckRepository, GetCheckReminders,
+    GetMigrations, MigrateRepository, CancelCommands, GetTotalStorageUsage, GetReclaimableSpace, GetLocks, BuildBackend, GetBackendParams,
+    RelocateRepository, GetRepoStatuses, GetStatsPolling, SetStatsPolling, GetRepositoryFormat,
+    GetRepositoryReachability, ClearCache, FindRepositories
 } from '../../wailsjs/go/main/App';
 
-interface Repo { id: string; name: string; uri: string; password: string; sourceFolders: string[]; excludes: string[]; }
-const empty = (): Repo => ({ id: '', name: '', uri: '', password: '', sourceFolders: [], excludes: [] });
+interface RepoFormat { version: number; id: string; compression: boolean; }
+interface RepoStatus { repoId: string; stats: { totalSize: number }; lastBackup: string; error?: string; }
+interface Repo { id: string; name: string; uri: string; password: string; sourceFolders: string[]; excludes: string[]; extraArgs: string[]; defaultTags: string[]; backupRetries: number; metadata?: Record<string, string>; preBackupCommand?: string; postBackupCommand?: string; ignorePreBackupErr?: boolean; priority?: string; excludeFiles?: string[]; checkIntervalDays?: number; checkReadDataSubset?: string; maxRepoSize?: number; quotaWarnOnly?: boolean; backendType?: string; env?: Record<string, string>; cacheDir?: string; }
+const BACKEND_FIELDS: Record<string, [string, string, boolean?][]> = {
+    local: [['path', 'Folder']],
+    sftp: [['user', 'User'], ['host', 'Host'], ['path', 'Path on the server']],
+    rest: [['endpoint', 'Server URL'], ['path', 'Path']],
+    s3: [['endpoint', 'Endpoint (empty = AWS)'], ['region', 'Region'], ['bucket', 'Bucket'], ['prefix', 'Prefix'], ['accessKey', 'Access key'], ['secretKey', 'Secret key', true]],
+    b2: [['accountId', 'Account ID / key ID'], ['accountKey', 'Application key', true], ['bucket', 'Bucket'], ['prefix', 'Prefix']],
+    azure: [['accountName', 'Account name'], ['accountKey', 'Account key', true], ['bucket', 'Container'], ['prefix', 'Prefix']],
+};
+
+const empty = (): Repo => ({ id: '', name: '', uri: '', password: '', sourceFolders: [], excludes: [], extraArgs: [], defaultTags: [], backupRetries: 0 });
+
+function fmtBytes(b: number) {
+    if (b < 1024) return b + ' B';
+    if (b < 1048576) return (b / 1024).toFixed(1) + ' KB';
+    if (b < 1073741824) return (b / 1048576).toFixed(1) + ' MB';
+    return (b / 1073741824).toFixed(2) + ' GB';
+}
 
 export default function Repositories() {
     const { addToast } = useToast();
@@ -18,15 +40,98 @@ export default function Repositories() {
     const [testing, setTesting] = useState(false);
     const [saving, setSaving] = useState(false);
     const [showPass, setShowPass] = useState(false);
+    const [progressLine, setProgressLine] = useState('');
+    const [totalSize, setTotalSize] = useState<number | null>(null);
+    const [sizing, setSizing] = useState(false);
+    const [format, setFormat] = useState<RepoFormat | null>(null);
+    const [reachable, setReachable] = useState<Record<string, { reachable: boolean; error?: string }>>({});
+    const [statuses, setStatuses] = useState<Record<string, RepoStatus>>({});
+    const [chunkerFrom, setChunkerFrom] = useState('');
+    const [backend, setBackend] = useState<Record<string, string>>({ type: '' });
+    const [backendErr, setBackendErr] = useState('');
+    const [query, setQuery] = useState('');
+    const [found, setFound] = useState<Repo[] | null>(null);
 
     const load = () => {
         setLoading(true);
-        GetRepositories().then((r: Repo[]) => { setRepos(r || []); setLoading(false); }).catch(() => setLoading(false));
+        GetRepositories().then((r: Repo[]) => {
+            setRepos(r || []); setLoading(false);
+            (r || []).forEach(repo => GetRepositoryReachability(repo.id, 0)
+                .then((st: { reachable: boolean; error?: string }) => setReachable(prev => ({ ...prev, [repo.id]: st })))
+                .catch(() => { }));
+        }).catch(() => setLoading(false));
     };
     useEffect(load, []);
+    useEffect(() => {
+        if (!query.trim()) { setFound(null); return; }
+        FindRepositories(query).then((r: Repo[]) => setFound(r || [])).catch(() => setFound(null));
+    }, [query, repos]);
+    useEffect(() => {
+        EventsOn('config:reloaded', () => {
+            load();
+            addToast({ type: 'info', title: 'Configuration reloaded', message: 'config.json was changed outside the app.' });
+        });
+        return () => EventsOff('config:reloaded');
+    }, []);
+    useEffect(() => {
+        GetRepoStatuses().then((list: RepoStatus[]) => {
+            const m: Record<string, RepoStatus> = {};
+            (list || []).forEach(st => { m[st.repoId] = st; });
+            setStatuses(m);
+        }).catch(() => { });
+        EventsOn('repo:statsupdated', (st: RepoStatus) => setStatuses(prev => ({ ...prev, [st.repoId]: st })));
+        EventsOn('check:finished', (c: { repoName: string; error?: string }) => addToast(c.error
+            ? { type: 'error', title: `Scheduled check failed: ${c.repoName}`, message: c.error }
+            : { type: 'success', title: `Scheduled check passed: ${c.repoName}` }));
+        return () => { EventsOff('repo:statsupdated'); EventsOff('check:finished'); };
+    }, []);
+    useEffect(() => {
+        GetCheckReminders(30).then((r: { repoName: string; daysSince: number }[]) => {
+            if (r && r.length > 0) addToast({
+                type: 'warning', title: 'Integrity check recommended',
+                message: r.map(x => x.daysSince < 0 ? `${x.repoName}: never checked` : `${x.repoName}: ${x.daysSince} days ago`).join('\n'),
+            });
+        }).catch(() => { });
+    }, []);
 
-    const openAdd = () => { setEditRepo(empty()); setIsEdit(false); setShowPass(false); setModal(true); };
-    const openEdit = (r: Repo) => { setEditRepo({ ...r }); setIsEdit(true); setShowPass(false); setModal(true); };
+    const loadStorage = (refresh: boolean) => {
+        setSizing(true);
+        GetTotalStorageUsage(refresh)
+            .then((u: { totalSize: number }) => setTotalSize(u.totalSize))
+            .catch((e: unknown) => addToast({ type: 'error', title: 'Storage usage failed', message: String(e) }))
+            .finally(() => setSizing(false));
+    };
+
+    const configurePolling = async () => {
+        try {
+            const cur: { minutes: number; pollOnMetered: boolean } = await GetStatsPolling();
+            const v = prompt('Refresh repository size and last backup every N minutes (0 = off):', String(cur.minutes));
+            if (v === null) return;
+            const minutes = parseInt(v, 10);
+            if (isNaN(minutes) || minutes < 0) { addToast({ type: 'warning', title: 'Invalid interval' }); return; }
+            const onMetered = minutes > 0 && confirm('Also poll on metered connections (e.g. mobile hotspot)?');
+            await SetStatsPolling(minutes, onMetered);
+            addToast({ type: 'success', title: minutes > 0 ? `Polling every ${minutes} min` : 'Polling disabled' });
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
+    const openAdd = () => { setEditRepo(empty()); setBackend({ type: '' }); setBackendErr(''); setIsEdit(false); setShowPass(false); setModal(true); };
+    const openEdit = (r: Repo) => {
+        setEditRepo({ ...r }); setIsEdit(true); setShowPass(false); setModal(true);
+        setBackend({ type: '' }); setBackendErr('');
+        if (r.backendType) GetBackendParams(r).then(p => setBackend({ ...p })).catch(() => { });
+        setFormat(null);
+        GetRepositoryFormat(r.id, false).then((f: RepoFormat) => setFormat(f)).catch(() => { });
+    };
+
+    const updateBackend = (field: string, value: string) => {
+        const next = { ...backend, [field]: value };
+        setBackend(next);
+        if (!next.type) { setEditRepo(p => ({ ...p, backendType: '' })); setBackendErr(''); return; }
+        BuildBackend(next as any)
+            .then(c => { setEditRepo(p => ({ ...p, backendType: next.type, uri: c.uri, env: c.env })); setBackendErr(''); })
+            .catch((e: unknown) => setBackendErr(String(e)));
+    };
 
     const save = async () => {
         if (!editRepo.name || !editRepo.uri || !editRepo.password) {
@@ -34,15 +139,35 @@ export default function Repositories() {
         }
         setSaving(true);
         try {
+            let warnings: string[] | null = null;
             if (isEdit) await UpdateRepository(editRepo);
-            else await AddRepository(editRepo);
+            else warnings = await AddRepository(editRepo);
             addToast({ type: 'success', title: isEdit ? 'Repository updated' : 'Repository added' });
+            (warnings || []).forEach(w => addToast({ type: 'warning', title: 'Warning', message: w }));
             setModal(false); load();
         } catch (e: unknown) {
             addToast({ type: 'error', title: 'Error', message: String(e) });
         } finally { setSaving(false); }
     };
 
+    const duplicate = async (id: string) => {
+        try {
+            const copy: Repo = await DuplicateRepository(id);
+            addToast({ type: 'info', title: 'Repository duplicated', message: 'Set the URI and password of the copy.' });
+            load(); openEdit(copy);
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
+    const rename = async (r: Repo) => {
+        const name = prompt('New name of the repository:', r.name);
+        if (!name || name.trim() === r.name) return;
+        try {
+            await RenameRepository(r.id, name);
+            addToast({ type: 'success', title: 'Repository renamed', message: name.trim() });
+            load();
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
     const del = async (id: string, name: string) => {
         if (!confirm(`Delete repository "${name}"?`)) return;
         try {
@@ -59,8 +184,11 @@ export default function Repositories() {
         setTesting(true);
         try {
             if (isEdit) {
-                const msg = await TestRepository(editRepo.id);
-                addToast({ type: 'success', title: msg });
+                const cfg: { version: number; id: string } = await TestRepository(editRepo.id);
+                addToast({
+                    type: 'success', title: 'Connection successful!',
+                    message: `Repository format v${cfg.version}${cfg.version >= 2 ? ' (compression supported)' : ''} · ID ${cfg.id.substring(0, 8)}`,
+                });
             } else {
                 addToast({ type: 'info', title: 'Tip', message: 'Save the repository first to test the connection.' });
             }
@@ -68,13 +196,90 @@ export default function Repositories() {
         finally { setTesting(false); }
     };
 
+    const check = async () => {
+        const subset = prompt('Also read part of the data? Enter e.g. 10%, 1/12 or 5G, or leave empty to check the structure only:', editRepo.checkReadDataSubset || '');
+        if (subset === null) return;
+        setTesting(true);
+        try {
+            EventsOn('check:progress', (p: { percent: number; message: string }) =>
+                setProgressLine(p.percent >= 0 ? `${p.percent.toFixed(0)}% ${p.message}` : p.message));
+            await CheckRepository(editRepo.id, subset.trim());
+            addToast({ type: 'success', title: 'Repository check passed' });
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Check failed', message: String(e) }); }
+        finally { EventsOff('check:progress'); setProgressLine(''); setTesting(false); }
+    };
+
+    const reclaimable = async () => {
+        setTesting(true);
+        try {
+            const r = await GetReclaimableSpace(editRepo.id, true);
+            addToast({
+                type: 'info', title: `${fmtBytes(r.reclaimableBytes)} reclaimable by prune`,
+                message: `${r.packsToDelete} packs to delete, ${r.packsToRepack} to repack`,
+            });
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Estimate failed', message: String(e) }); }
+        finally { setTesting(false); }
+    };
+
+    const showLocks = async () => {
+        setTesting(true);
+        try {
+            const locks = (await GetLocks(editRepo.id)) || [];
+            if (locks.length === 0) { addToast({ type: 'info', title: 'Repository is not locked' }); return; }
+            addToast({
+                type: locks.some(l => l.stale) ? 'warning' : 'info', title: `${locks.length} lock(s) held`,
+                message: locks.map(l => `${l.exclusive ? 'exclusive' : 'shared'} by ${l.username}@${l.hostname} (PID ${l.pid}), refreshed ${Math.round(l.ageSeconds / 60)} min ago${l.stale ? ', stale' : ''}`).join('\n'),
+            });
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Failed to list locks', message: String(e) }); }
+        finally { setTesting(false); }
+    };
+
+    const migrate = async () => {
+        setTesting(true);
+        try {
+            const available: { name: string; description: string }[] = (await GetMigrations(editRepo.id)) || [];
+            if (available.length === 0) { addToast({ type: 'info', title: 'Repository is up to date', message: 'No migrations available.' }); return; }
+            const list = available.map(m => `${m.name} – ${m.description}`).join('\n');
+            const name = available.length === 1 ? available[0].name : prompt(`Available migrations:\n${list}\n\nName of the migration to run:`, available[0].name);
+            if (!name) return;
+            if (!confirm(`Run migration "${name}"?\n\nThis cannot be undone. Older restic versions will no longer be able to read this repository.`)) return;
+            EventsOn('migrate:progress', (line: string) => setProgressLine(line));
+            await MigrateRepository(editRepo.id, name);
+            addToast({ type: 'success', title: 'Migration completed', message: name });
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Migration failed', message: String(e) }); }
+        finally { EventsOff('migrate:progress'); setProgressLine(''); setTesting(false); }
+    };
+
+    const relocate = async () => {
+        const uri = prompt('New location of this repository (URI):', editRepo.uri);
+        if (!uri || uri === editRepo.uri) return;
+        setTesting(true);
+        try {
+            await RelocateRepository(editRepo.id, uri);
+            setEditRepo(p => ({ ...p, uri }));
+            addToast({ type: 'success', title: 'Repository relocated', message: uri });
+            load();
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Relocation refused', message: String(e) }); }
+        finally { setTesting(false); }
+    };
+
+    const clearCache = async () => {
+        if (!confirm('Delete the local cache of this repository?\nrestic rebuilds it on the next access.')) return;
+        setTesting(true);
+        try {
+            await ClearCache(editRepo.id);
+            addToast({ type: 'success', title: 'Cache cleared' });
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+        finally { setTesting(false); }
+    };
+
     const initRepo = async () => {
         if (!editRepo.name || !editRepo.uri || !editRepo.password) {
             addToast({ type: 'warning', title: 'Please fill in all fields.' }); return;
         }
         setSaving(true);
         try {
-            await InitRepository(editRepo);
+            await InitRepository(editRepo, chunkerFrom);
             addToast({ type: 'success', title: 'Repository initialized!', message: editRepo.uri });
         } catch (e: unknown) { addToast({ type: 'error', title: 'Initialization failed', message: String(e) }); }
         finally { setSaving(false); }
@@ -87,8 +292,18 @@ export default function Repositories() {
                     <h2 style={{ fontSize: 20, fontWeight: 700 }}>Repositories</h2>
                     <p style={{ fontSize: 13, color: 'var(--text-3)', marginTop: 4 }}>Manage your Restic repositories</p>
                 </div>
+                <button className="btn btn-ghost btn-sm" onClick={configurePolling} title="Background refresh of size and last backup">⏱</button>
+                <button className="btn btn-ghost btn-sm" onClick={() => loadStorage(totalSize !== null)} disabled={sizing}
+                    title="Storage used by all repositories">
+                    {sizing ? <><span className="spinner" />Calculating...</> : totalSize === null ? '💾 Total storage' : `💾 ${fmtBytes(totalSize)} ↻`}
+                </button>
                 <button className="btn btn-primary" onClick={openAdd}>+ New</button>
             </div>
+            {repos.length > 5 && (
+                <input style={{ width: '100%', marginBottom: 16 }} placeholder="Filter by name, URI or metadata…"
+                    value={query} onChange={e => setQuery(e.target.value)}
+                    onKeyDown={e => { if (e.key === 'Enter' && found && found.length > 0) openEdit(found[0]); }} />
+            )}
 
             {loading ? (
                 <div className="repo-grid">
@@ -96,17 +311,34 @@ export default function Repositories() {
                 </div>
             ) : (
                 <div className="repo-grid">
-                    {repos.map(r => (
+                    {(found ?? repos).map(r => (
                         <div key={r.id} className="repo-card" onClick={() => openEdit(r)}>
                             <div className="row" style={{ marginBottom: 8 }}>
                                 <span style={{ fontSize: 20 }}>🗄️</span>
                                 <div className="grow">
-                                    <div className="repo-name">{r.name}</div>
+                                    <div className="repo-name">
+                                        {reachable[r.id] && (
+                                            <span title={reachable[r.id].reachable ? 'Reachable' : reachable[r.id].error} style={{ marginRight: 6, fontSize: 10 }}>
+                                                {reachable[r.id].reachable ? '🟢' : '🔴'}
+                                            </span>
+                                        )}
+                                        {r.name}
+                                    </div>
                                 </div>
                             </div>
                             <div className="repo-uri">{r.uri}</div>
+                            {statuses[r.id] && !statuses[r.id].error && (
+                                <div style={{ fontSize: 12, color: 'var(--text-3)', marginTop: 4 }}>
+                                    💾 {fmtBytes(statuses[r.id].stats.totalSize)} · Last backup: {statuses[r.id].lastBackup ? new Date(statuses[r.id].lastBackup).toLocaleString() : 'never'}
+                                </div>
+                            )}
+                            {r.metadata?.notes && (
+                                <div style={{ fontSize: 12, color: 'var(--text-3)', marginTop: 4 }}>{r.metadata.notes}</div>
+                            )}
                             <div className="repo-actions" onClick={e => e.stopPropagation()}>
                                 <button className="btn btn-ghost btn-sm" onClick={() => openEdit(r)}>✏️ Edit</button>
+                                <button className="btn btn-ghost btn-sm" onClick={() => rename(r)} title="Rename">🏷️</button>
+                                <button className="btn btn-ghost btn-sm" onClick={() => duplicate(r.id)} title="Duplicate">📋</button>
                                 <button className="btn btn-danger btn-sm" onClick={() => del(r.id, r.name)}>🗑️</button>
                             </div>
                         </div>
@@ -134,6 +366,11 @@ export default function Repositories() {
                             <div className="modal-title">{isEdit ? 'Edit Repository' : 'New Repository'}</div>
                             <span className="modal-close" onClick={() => setModal(false)}>✕</span>
                         </div>
+                        {isEdit && format && (
+                            <div style={{ fontSize: 12, color: 'var(--text-3)', marginBottom: 12 }}>
+                                Format v{format.version} · {format.compression ? 'compression supported' : 'no compression'} · ID {format.id.substring(0, 8)}
+                            </div>
+                        )}
 
                         <div className="form-group">
                             <label>Name</label>
@@ -141,9 +378,25 @@ export default function Repositories() {
                                 onChange={e => setEditRepo(p => ({ ...p, name: e.target.value }))} />
                         </div>
                         <div className="form-group">
+                            <label>Storage type</label>
+                            <select value={backend.type || ''} onChange={e => updateBackend('type', e.target.value)}>
+                                <option value="">Enter URI manually</option>
+                                <option value="local">Local folder</option>
+                                <option value="sftp">SFTP</option>
+                                <option value="rest">REST server</option>
+                                <option value="s3">S3 / compatible</option>
+                                <option value="b2">Backblaze B2</option>
+                                <option value="azure">Azure Blob Storage</option>
+                            </select>
+                            {(BACKEND_FIELDS[backend.type] || []).map(([field, label, secret]) => (
+                                <input key={field} placeholder={label} type={secret && !showPass ? 'password' : 'text'}
+                                    value={backend[field] || ''} onChange={e => updateBackend(field, e.target.value)} />
+                            ))}
+                            {backendErr && <div style={{ fontSize: 12, color: 'var(--text-3)' }}>{backendErr}</div>}
                             <label>Repository URI</label>
-                            <input placeholder="sftp:root@192.168.178.96:/backup" value={editRepo.uri}
+                            <input placeholder="sftp:root@192.168.178.96:/backup" value={editRepo.uri} readOnly={!!backend.type}
                                 onChange={e => setEditRepo(p => ({ ...p, uri: e.target.value }))} />
+                            <div style={{ fontSize: 12, color: 'var(--text-3)' }}>${'{VAR}'} and %VAR% are replaced with environment variables, e.g. ${'{BACKUP_MOUNT}'}/restic</div>
                         </div>
                         <div className="form-group">
                             <label>Password</label>
@@ -158,14 +411,119 @@ export default function Repositories() {
                             </div>
                         </div>
 
+                        <div className="form-group">
+                            <label>Notes</label>
+                            <input placeholder="e.g. Client A – production server" value={editRepo.metadata?.notes || ''}
+                                onChange={e => setEditRepo(p => ({ ...p, metadata: { ...(p.metadata || {}), notes: e.target.value } }))} />
+                        </div>
+
+                        <details className="form-group">
+                            <summary>Advanced settings</summary>
+                            <label>Extra restic flags</label>
+                            <input placeholder="e.g. --insecure-tls --limit-upload=500"
+                                defaultValue={(editRepo.extraArgs || []).join(' ')}
+                                onBlur={e => setEditRepo(p => ({ ...p, extraArgs: e.target.value.split(' ').filter(Boolean) }))} />
+                            <label>Default tags</label>
+                            <input placeholder="e.g. work, laptop"
+                                defaultValue={(editRepo.defaultTags || []).join(', ')}
+                                onBlur={e => setEditRepo(p => ({ ...p, defaultTags: e.target.value.split(',').map(t => t.trim()).filter(Boolean) }))} />
+                            <label>Retries on network errors</label>
+                            <input type="number" min={0} max={10} value={editRepo.backupRetries || 0}
+                                onChange={e => setEditRepo(p => ({ ...p, backupRetries: Number(e.target.value) }))} />
+                            <label>Exclude files (one path per line, applied in order)</label>
+                            <textarea rows={2} placeholder={'C:\\backup\\common-excludes.txt'}
+                                defaultValue={(editRepo.excludeFiles || []).join('\n')}
+                                onBlur={e => setEditRepo(p => ({ ...p, excludeFiles: e.target.value.split('\n').map(t => t.trim()).filter(Boolean) }))} />
+                            <label>CPU priority for backup and prune</label>
+                            <select value={editRepo.priority || ''} onChange={e => setEditRepo(p => ({ ...p, priority: e.target.value }))}>
+                                <option value="">Normal</option>
+                                <option value="low">Low</option>
+                                <option value="idle">Idle (only when the computer is idle)</option>
+                            </select>
+                            <label>Cache directory (empty = shared restic cache)</label>
+                            <input placeholder="e.g. D:\restic-cache\nas" value={editRepo.cacheDir || ''}
+                                onChange={e => setEditRepo(p => ({ ...p, cacheDir: e.target.value }))} />
+                            <label>Command before backup</label>
+                            <input placeholder="e.g. pg_dump -f C:\dumps\db.sql mydb" value={editRepo.preBackupCommand || ''}
+                                onChange={e => setEditRepo(p => ({ ...p, preBackupCommand: e.target.value }))} />
+                            <label style={{ display: 'flex', alignItems: 'center', gap: 8, fontSize: 13 }}>
+                                <input type="checkbox" checked={!!editRepo.ignorePreBackupErr}
+                                    onChange={e => setEditRepo(p => ({ ...p, ignorePreBackupErr: e.target.checked }))} />
+                                Back up even if this command fails
+                            </label>
+                            <label>Command after backup</label>
+                            <input placeholder="Runs after every backup; RESTIC_GUI_BACKUP_STATUS is success or failure" value={editRepo.postBackupCommand || ''}
+                                onChange={e => setEditRepo(p => ({ ...p, postBackupCommand: e.target.value }))} />
+                            <label>Automatic integrity check every N days (0 = off)</label>
+                            <input type="number" min={0} value={editRepo.checkIntervalDays || 0}
+                                onChange={e => setEditRepo(p => ({ ...p, checkIntervalDays: Number(e.target.value) }))} />
+                            <label>Data to read during the automatic check</label>
+                            <input placeholder="e.g. 10%, 1/12 or 5G; empty = structure only" value={editRepo.checkReadDataSubset || ''}
+                                onChange={e => setEditRepo(p => ({ ...p, checkReadDataSubset: e.target.value }))} />
+                            <label>Size limit in GB (0 = none)</label>
+                            <input type="number" min={0} step="any" value={(editRepo.maxRepoSize || 0) / 1e9}
+                                onChange={e => setEditRepo(p => ({ ...p, maxRepoSize: Math.round(Number(e.target.value) * 1e9) }))} />
+                            <label style={{ display: 'flex', alignItems: 'center', gap: 8, fontSize: 13 }}>
+                                <input type="checkbox" checked={!!editRepo.quotaWarnOnly}
+                                    onChange={e => setEditRepo(p => ({ ...p, quotaWarnOnly: e.target.checked }))} />
+                                Only warn when the limit would be exceeded
+                            </label>
+                        </details>
+
                         <div style={{ display: 'flex', gap: 8, marginBottom: 8 }}>
                             <button className="btn btn-ghost btn-sm" onClick={test} disabled={testing}>
                                 {testing ? <><span className="spinner" />Testing...</> : '🔌 Test connection'}
                             </button>
+                            {testing && (
+                                <button className="btn btn-danger btn-sm" onClick={() => CancelCommands()}>✕ Cancel</button>
+                            )}
+                            {isEdit && (
+                                <button className="btn btn-ghost btn-sm" onClick={check} disabled={testing}>
+                                    🩺 Check integrity
+                                </button>
+                            )}
+                            {isEdit && (
+                                <button className="btn btn-ghost btn-sm" onClick={reclaimable} disabled={testing}
+                                    title="Runs prune --dry-run; nothing is changed">
+                                    ♻️ Reclaimable space
+                                </button>
+                            )}
+                            {isEdit && (
+                                <button className="btn btn-ghost btn-sm" onClick={showLocks} disabled={testing}>
+                                    🔒 Locks
+                                </button>
+                            )}
+                            {isEdit && (
+                                <button className="btn btn-ghost btn-sm" onClick={migrate} disabled={testing}>
+                                    ⬆️ Upgrade format
+                                </button>
+                            )}
+                            {isEdit && (
+                                <button className="btn btn-ghost btn-sm" onClick={relocate} disabled={testing}>
+                                    🚚 Relocate
+                                </button>
+                            )}
+                            {isEdit && (
+                                <button className="btn btn-ghost btn-sm" onClick={clearCache} disabled={testing}>
+                                    🧹 Clear cache
+                                </button>
+                            )}
                             <button className="btn btn-ghost btn-sm" onClick={initRepo} disabled={saving}>
                                 🆕 Initialize repository
                             </button>
+                            {repos.length > 0 && (
+                                <select value={chunkerFrom} onChange={e => setChunkerFrom(e.target.value)}
+                                    title="Share chunker parameters with an existing repository so restic copy keeps deduplication">
+                                    <option value="">Own chunker parameters</option>
+                                    {repos.filter(r => r.id !== editRepo.id).map(r => (
+                                        <option key={r.id} value={r.id}>Copy-compatible with {r.name}</option>
+                                    ))}
+                                </select>
+                            )}
                         </div>
+                        {progressLine && (
+                            <div style={{ fontSize: 12, color: 'var(--text-3)', marginBottom: 8 }}>{progressLine}</div>
+                        )}
 
                         <div className="modal-footer">
                             <button className="btn btn-secondary" onClick={() => setModal(false)}>Cancel</button>
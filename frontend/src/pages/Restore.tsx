@@ -2,7 +2,8 @@ import React, { useState, useEffect } from 'react';
 import { useToast } from '../ToastContext';
 import { EventsOn, EventsOff } from '../../wailsjs/runtime/runtime';
 import {
-    GetRepositories, GetSnapshots, StartRestore, CancelRestore, SelectRestoreFolder
+    GetRepositories, GetSnapshots, StartRestore, CancelRestore, SelectRestoreFolder,
+    GetRestoreManifests, ResumeRestore, DiscardRestoreManifest, FindSnapshotByDate
 } from '../../wailsjs/go/main/App';
 
 interface Repo { id: string; name: string; }
@@ -10,9 +11,14 @@ interface Snapshot { id: string; short_id: string; time: string; hostname: strin
 interface Progress {
     message_type: string; percent_done: number;
     total_files: number; files_restored: number;
-    total_bytes: number; bytes_restored: number;
+    total_bytes: number; bytes_restored: number; files_skipped?: number;
     seconds_elapsed: number;
 }
+interface Manifest {
+    id: string; updated: string;
+    restore: { repoName: string; snapshotId: string; target: string; toOriginal: boolean; paths: string[] | null };
+    bytesDone: number; totalBytes: number;
+}
 
 function fmt(b: number): string {
     if (b < 1024) return b + ' B';
@@ -34,8 +40,15 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
     const [targetPath, setTargetPath] = useState('');
     const [status, setStatus] = useState<'idle' | 'running' | 'done' | 'error'>('idle');
     const [progress, setProgress] = useState<Progress | null>(null);
+    const [summary, setSummary] = useState<Progress | null>(null);
     const [errMsg, setErrMsg] = useState('');
+    const [verify, setVerify] = useState(false);
+    const [failures, setFailures] = useState<{ path: string; message: string }[]>([]);
     const [loadingSnaps, setLoadingSnaps] = useState(false);
+    const [manifests, setManifests] = useState<Manifest[]>([]);
+
+    const loadManifests = () => { GetRestoreManifests().then((m: Manifest[]) => setManifests(m || [])).catch(() => { }); };
+    useEffect(loadManifests, [status]);
 
     useEffect(() => {
         GetRepositories().then((r: Repo[]) => {
@@ -56,9 +69,10 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
 
     useEffect(() => {
         EventsOn('restore:progress', (p: Progress) => setProgress(p));
-        EventsOn('restore:complete', () => setStatus('done'));
+        EventsOn('restore:complete', (s: Progress) => { setSummary(s); setStatus('done'); });
         EventsOn('restore:error', (msg: string) => { setStatus('error'); setErrMsg(msg); });
-        return () => { EventsOff('restore:progress'); EventsOff('restore:complete'); EventsOff('restore:error'); };
+        EventsOn('restore:verify', (v: { failures: { path: string; message: string }[] | null }) => setFailures(v.failures || []));
+        return () => { EventsOff('restore:progress'); EventsOff('restore:complete'); EventsOff('restore:error'); EventsOff('restore:verify'); };
     }, []);
 
     const pickFolder = async () => {
@@ -70,8 +84,8 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
         if (!selectedRepo) { addToast({ type: 'warning', title: 'Please select a repository' }); return; }
         if (!selectedSnap) { addToast({ type: 'warning', title: 'Please select a snapshot' }); return; }
         if (!targetPath) { addToast({ type: 'warning', title: 'Please select a target folder' }); return; }
-        setStatus('running'); setProgress(null); setErrMsg('');
-        try { await StartRestore(selectedRepo, selectedSnap, targetPath); }
+        setStatus('running'); setProgress(null); setErrMsg(''); setFailures([]);
+        try { await StartRestore(selectedRepo, selectedSnap, targetPath, verify); }
         catch (e: unknown) { setStatus('error'); setErrMsg(String(e)); }
     };
 
@@ -81,9 +95,27 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
         addToast({ type: 'info', title: 'Restore cancelled' });
     };
 
+    const resume = async (m: Manifest) => {
+        setStatus('running'); setProgress(null); setErrMsg(''); setTargetPath(m.restore.target);
+        try { await ResumeRestore(m.id); }
+        catch (e: unknown) { setStatus('error'); setErrMsg(String(e)); }
+    };
+
+    const discard = async (id: string) => {
+        try { await DiscardRestoreManifest(id); loadManifests(); }
+        catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
     const pct = progress ? Math.round(progress.percent_done * 100) : 0;
     const snap = snapshots.find(s => s.id === selectedSnap);
 
+    const pickDate = (value: string) => {
+        if (!value) return;
+        FindSnapshotByDate(selectedRepo, new Date(value).toISOString(), '')
+            .then((s: Snapshot) => setSelectedSnap(s.id))
+            .catch((e: unknown) => addToast({ type: 'warning', title: 'No snapshot found', message: String(e) }));
+    };
+
     return (
         <div>
             <div className="row" style={{ marginBottom: 20 }}>
@@ -93,6 +125,24 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
                 </div>
             </div>
 
+            {status === 'idle' && manifests.length > 0 && (
+                <div className="card section">
+                    <div className="card-header"><div className="card-title">⏸ Interrupted restores</div></div>
+                    {manifests.map(m => (
+                        <div key={m.id} className="row" style={{ marginBottom: 8, fontSize: 13 }}>
+                            <div className="grow">
+                                <strong>{m.restore.repoName}</strong> · {m.restore.snapshotId.slice(0, 8)} → {m.restore.toOriginal ? 'original location' : m.restore.target}
+                                <div style={{ fontSize: 12, color: 'var(--text-3)' }}>
+                                    {m.totalBytes > 0 ? `${fmt(m.bytesDone)} of ${fmt(m.totalBytes)}` : 'not started'} · {fmtDate(m.updated)}
+                                </div>
+                            </div>
+                            <button className="btn btn-primary btn-sm" onClick={() => resume(m)}>▶ Resume</button>
+                            <button className="btn btn-ghost btn-sm" onClick={() => discard(m.id)}>✕</button>
+                        </div>
+                    ))}
+                </div>
+            )}
+
             <div className="card section">
                 <div style={{ display: 'grid', gridTemplateColumns: '1fr 1fr', gap: 16 }}>
                     <div className="form-group" style={{ margin: 0 }}>
@@ -113,6 +163,11 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
                         </select>
                     </div>
                 </div>
+                <div className="form-group" style={{ marginTop: 12, marginBottom: 0 }}>
+                    <label>Restore as of</label>
+                    <input type="datetime-local" onChange={e => pickDate(e.target.value)}
+                        disabled={status === 'running' || !selectedRepo} />
+                </div>
                 {snap && (
                     <div style={{ marginTop: 10, fontSize: 12, color: 'var(--text-3)' }}>
                         Backed-up paths: {snap.paths.join(', ')}
@@ -127,6 +182,10 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
                         onClick={pickFolder} />
                     <button className="btn btn-secondary" onClick={pickFolder} disabled={status === 'running'}>Browse</button>
                 </div>
+                <label style={{ display: 'flex', alignItems: 'center', gap: 8, marginTop: 10, fontSize: 13, color: 'var(--text-2)' }}>
+                    <input type="checkbox" checked={verify} onChange={e => setVerify(e.target.checked)} />
+                    Verify restored files against the snapshot (slower, re-reads every file)
+                </label>
             </div>
 
             {status === 'idle' && (
@@ -173,6 +232,12 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
                     <div style={{ color: 'var(--success)', fontWeight: 700, fontSize: 16, marginBottom: 8 }}>
                         ✅ Restore complete!
                     </div>
+                    {summary && (
+                        <p style={{ fontSize: 13, color: 'var(--text-2)', marginBottom: 4 }}>
+                            Restored {summary.files_restored.toLocaleString()} files ({fmt(summary.bytes_restored)})
+                            {summary.files_skipped ? `, skipped ${summary.files_skipped.toLocaleString()}` : ''}
+                        </p>
+                    )}
                     <p style={{ fontSize: 13, color: 'var(--text-2)' }}>Files restored to <code>{targetPath}</code>.</p>
                     <div style={{ marginTop: 16 }}>
                         <button className="btn btn-secondary" onClick={() => { setStatus('idle'); setTargetPath(''); }}>
@@ -186,6 +251,11 @@ export default function Restore({ initRepoId = '', initSnapshotId = '' }: { init
                 <div className="status-card" style={{ borderColor: 'var(--danger)' }}>
                     <div style={{ color: 'var(--danger)', fontWeight: 700, marginBottom: 8 }}>❌ Restore failed</div>
                     <p style={{ fontSize: 13, color: 'var(--text-2)' }}>{errMsg}</p>
+                    {failures.length > 0 && (
+                        <ul style={{ fontSize: 12, color: 'var(--text-2)', marginTop: 8, maxHeight: 200, overflowY: 'auto' }}>
+                            {failures.map(f => <li key={f.path}><code>{f.path}</code>: {f.message}</li>)}
+                        </ul>
+                    )}
                     <div style={{ marginTop: 16 }}>
                         <button className="btn btn-secondary" onClick={() => setStatus('idle')}>↩ Back</button>
                     </div>
@@ -3,7 +3,7 @@ import { useToast } from '../ToastContext';
 import { EventsOn, EventsOff } from '../../wailsjs/runtime/runtime';
 import {
     GetRepositories, GetSnapshots,
-    ListSnapshotContents, RestoreSelected, SelectRestoreFolder
+    ListSnapshotContents, CancelSnapshotListing, SuggestPaths, RestoreSelected, GetRestoreConflicts, RestoreResolved, SelectRestoreFolder, CatFile
 } from '../../wailsjs/go/main/App';
 
 interface Repo { id: string; name: string; }
@@ -11,8 +11,10 @@ interface Snapshot { id: string; short_id: string; time: string; hostname: strin
 interface FileNode { name: string; type: string; path: string; size: number; mtime: string; }
 interface RestoreProgress {
     percent_done: number; total_files: number; files_restored: number;
-    total_bytes: number; bytes_restored: number; seconds_elapsed: number;
+    total_bytes: number; bytes_restored: number; seconds_elapsed: number; files_skipped?: number;
 }
+interface FileContent { path: string; content: string; encoding: string; binary: boolean; truncated: boolean; }
+interface CopyProgress { current_path: string; bytes_copied: number; total_bytes: number; percent_done: number; }
 
 function norm(p: string): string {
     let s = p.replace(/\\/g, '/');
@@ -61,14 +63,24 @@ export default function SelectiveRestore({
     const [selectedSnap, setSelectedSnap] = useState(initSnapshotId);
     const [nodes, setNodes] = useState<FileNode[]>([]);
     const [loadingNodes, setLoadingNodes] = useState(false);
+    const [listed, setListed] = useState(0);
+    const [jumpPath, setJumpPath] = useState('');
+    const [conflicts, setConflicts] = useState<{ path: string; localPath: string; diskMTime: string; snapshotMTime: string }[] | null>(null);
+    const [overwrite, setOverwrite] = useState<Set<string>>(new Set());
+    const [suggestions, setSuggestions] = useState<FileNode[]>([]);
     const [loadingSnaps, setLoadingSnaps] = useState(false);
     const [expanded, setExpanded] = useState<Set<string>>(new Set());
     const [checked, setChecked] = useState<Set<string>>(new Set());
     const [targetPath, setTargetPath] = useState('');
     const [restoreMode, setRestoreMode] = useState<'original' | 'custom'>('original');
+    const [flatten, setFlatten] = useState(false);
     const [status, setStatus] = useState<'idle' | 'running' | 'done' | 'error'>('idle');
     const [progress, setProgress] = useState<RestoreProgress | null>(null);
+    const [summary, setSummary] = useState<RestoreProgress | null>(null);
     const [errMsg, setErrMsg] = useState('');
+    const [verify, setVerify] = useState(false);
+    const [failures, setFailures] = useState<{ path: string; message: string }[]>([]);
+    const [preview, setPreview] = useState<FileContent | null>(null);
 
     useEffect(() => {
         GetRepositories().then((r: Repo[]) => {
@@ -92,19 +104,31 @@ export default function SelectiveRestore({
 
     useEffect(() => {
         if (!selectedRepo || !selectedSnap) return;
-        setLoadingNodes(true);
+        setLoadingNodes(true); setListed(0);
         setNodes([]); setChecked(new Set()); setExpanded(new Set());
+        EventsOn('ls:progress', (p: { snapshotId: string; entries: number }) => {
+            if (p.snapshotId === selectedSnap) setListed(p.entries);
+        });
         ListSnapshotContents(selectedRepo, selectedSnap)
             .then((n: FileNode[]) => setNodes(n || []))
-            .catch((e: unknown) => addToast({ type: 'error', title: 'Failed to load snapshot contents', message: String(e) }))
-            .finally(() => setLoadingNodes(false));
+            .catch((e: unknown) => {
+                if (String(e) !== 'cancelled') addToast({ type: 'error', title: 'Failed to load snapshot contents', message: String(e) });
+            })
+            .finally(() => { setLoadingNodes(false); EventsOff('ls:progress'); });
     }, [selectedSnap]);
 
     useEffect(() => {
         EventsOn('restore:progress', (p: RestoreProgress) => setProgress(p));
-        EventsOn('restore:complete', () => setStatus('done'));
+        EventsOn('restore:copying', (c: CopyProgress) => setProgress(p => p && ({
+            ...p, percent_done: c.percent_done, bytes_restored: c.bytes_copied, total_bytes: c.total_bytes,
+        })));
+        EventsOn('restore:complete', (s: RestoreProgress) => { setSummary(s); setStatus('done'); });
         EventsOn('restore:error', (msg: string) => { setStatus('error'); setErrMsg(msg); });
-        return () => { EventsOff('restore:progress'); EventsOff('restore:complete'); EventsOff('restore:error'); };
+        EventsOn('restore:verify', (v: { failures: { path: string; message: string }[] | null }) => setFailures(v.failures || []));
+        return () => {
+            EventsOff('restore:progress'); EventsOff('restore:copying');
+            EventsOff('restore:complete'); EventsOff('restore:error'); EventsOff('restore:verify');
+        };
     }, []);
 
     const visibleNodes = useMemo(() =>
@@ -153,6 +177,23 @@ export default function SelectiveRestore({
         return 'indeterminate';
     };
 
+    const typePath = (value: string) => {
+        setJumpPath(value);
+        const match = suggestions.find(n => n.path === value);
+        if (match) {
+            setExpanded(prev => {
+                const next = new Set(prev);
+                for (let p = parentOf(match.path); p; p = parentOf(p)) next.add(p);
+                return next;
+            });
+            if (!checked.has(match.path)) toggleCheck(match, { stopPropagation: () => { } } as React.MouseEvent);
+            setJumpPath('');
+            return;
+        }
+        if (value.length < 2) { setSuggestions([]); return; }
+        SuggestPaths(selectedRepo, selectedSnap, value).then(r => setSuggestions(r || [])).catch(() => setSuggestions([]));
+    };
+
     const selectAll = () => setChecked(new Set(nodes.map(n => n.path)));
     const selectNone = () => setChecked(new Set());
 
@@ -164,15 +205,41 @@ export default function SelectiveRestore({
     const startRestore = async () => {
         if (checked.size === 0) { addToast({ type: 'warning', title: 'No entries selected' }); return; }
         if (restoreMode === 'custom' && !targetPath) { addToast({ type: 'warning', title: 'Please select a target folder' }); return; }
-        setStatus('running'); setProgress(null); setErrMsg('');
+        if (restoreMode === 'original') {
+            try {
+                const found = (await GetRestoreConflicts(selectedRepo, selectedSnap, Array.from(checked))) || [];
+                if (found.length > 0) { setOverwrite(new Set()); setConflicts(found); return; }
+            } catch (e: unknown) { addToast({ type: 'error', title: 'Conflict check failed', message: String(e) }); return; }
+        }
+        setStatus('running'); setProgress(null); setErrMsg(''); setFailures([]);
+        try {
+            await RestoreSelected(selectedRepo, selectedSnap, Array.from(checked), targetPath, restoreMode === 'original', restoreMode === 'custom' && flatten, verify);
+        } catch (e: unknown) { setStatus('error'); setErrMsg(String(e)); }
+    };
+
+    const restoreResolved = async () => {
+        setConflicts(null);
+        setStatus('running'); setProgress(null); setErrMsg(''); setFailures([]);
         try {
-            await RestoreSelected(selectedRepo, selectedSnap, Array.from(checked), targetPath, restoreMode === 'original');
+            await RestoreResolved(selectedRepo, selectedSnap, Array.from(checked), Array.from(overwrite), verify);
         } catch (e: unknown) { setStatus('error'); setErrMsg(String(e)); }
     };
 
+    const toggleOverwrite = (path: string) => setOverwrite(prev => {
+        const next = new Set(prev);
+        if (next.has(path)) next.delete(path); else next.add(path);
+        return next;
+    });
+
     const pct = progress ? Math.round(progress.percent_done * 100) : 0;
     const snap = snapshots.find(s => s.id === selectedSnap);
 
+    const openPreview = (path: string) => {
+        CatFile(selectedRepo, selectedSnap, path)
+            .then((c: FileContent) => setPreview(c))
+            .catch((e: unknown) => addToast({ type: 'error', title: 'Preview failed', message: String(e) }));
+    };
+
     return (
         <div>
             <div className="row" style={{ marginBottom: 20 }}>
@@ -225,6 +292,11 @@ export default function SelectiveRestore({
                     </div>
                     {nodes.length > 0 && (
                         <div style={{ display: 'flex', gap: 8, alignItems: 'center' }}>
+                            <input list="path-suggestions" placeholder="Type a path to select it..." value={jumpPath}
+                                onChange={e => typePath(e.target.value)} style={{ width: 260 }} />
+                            <datalist id="path-suggestions">
+                                {suggestions.map(n => <option key={n.path} value={n.path}>{n.type === 'dir' ? '📁' : '📄'}</option>)}
+                            </datalist>
                             <button className="btn btn-ghost btn-sm" onClick={selectAll}>All</button>
                             <button className="btn btn-ghost btn-sm" onClick={selectNone}>None</button>
                             <span style={{ fontSize: 12, color: 'var(--accent)', minWidth: 80, textAlign: 'right' }}>
@@ -237,7 +309,8 @@ export default function SelectiveRestore({
                 {loadingNodes ? (
                     <div style={{ display: 'flex', alignItems: 'center', gap: 10, padding: '20px 0', color: 'var(--text-3)' }}>
                         <span className="spinner" style={{ borderTopColor: 'var(--accent)' }} />
-                        Loading snapshot contents... (may take a moment for large repos)
+                        Loading snapshot contents... {listed > 0 ? `${listed.toLocaleString()} entries so far` : '(may take a moment for large repos)'}
+                        <button className="btn btn-secondary btn-sm" onClick={() => CancelSnapshotListing()}>Cancel</button>
                     </div>
                 ) : nodes.length === 0 ? (
                     <div style={{ color: 'var(--text-3)', fontSize: 13, padding: '12px 0' }}>
@@ -324,6 +397,11 @@ export default function SelectiveRestore({
                                             {node.name}
                                         </span>
 
+                                        {node.type === 'file' && (
+                                            <span style={{ fontSize: 12, cursor: 'pointer', flexShrink: 0 }} title="Preview"
+                                                onClick={() => openPreview(node.path)}>👁</span>
+                                        )}
+
                                         <span style={{ fontSize: 11, color: 'var(--text-3)', flexShrink: 0 }}>
                                             {!isDir && fmt(node.size)}
                                         </span>
@@ -375,6 +453,17 @@ export default function SelectiveRestore({
                         </div>
                     )}
 
+                    {restoreMode === 'custom' && (
+                        <label style={{ display: 'flex', alignItems: 'center', gap: 8, marginTop: 10, fontSize: 13, color: 'var(--text-2)' }}>
+                            <input type="checkbox" checked={flatten} onChange={e => setFlatten(e.target.checked)} />
+                            Place selected items directly in the target folder (without parent folders)
+                        </label>
+                    )}
+                    <label style={{ display: 'flex', alignItems: 'center', gap: 8, marginTop: 10, fontSize: 13, color: 'var(--text-2)' }}>
+                        <input type="checkbox" checked={verify} onChange={e => setVerify(e.target.checked)} />
+                        Verify restored files against the snapshot (slower, re-reads every file)
+                    </label>
+
                     <div style={{ marginTop: 16 }}>
                         <button
                             className="btn btn-primary btn-lg"
@@ -421,6 +510,12 @@ export default function SelectiveRestore({
                     <div style={{ color: 'var(--success)', fontWeight: 700, fontSize: 16, marginBottom: 8 }}>
                         ✅ Restore complete!
                     </div>
+                    {summary && (
+                        <p style={{ fontSize: 13, color: 'var(--text-2)', marginBottom: 4 }}>
+                            Restored {summary.files_restored.toLocaleString()} files ({fmt(summary.bytes_restored)})
+                            {summary.files_skipped ? `, skipped ${summary.files_skipped.toLocaleString()}` : ''}
+                        </p>
+                    )}
                     <p style={{ fontSize: 13, color: 'var(--text-2)' }}>
                         {restoreMode === 'original'
                             ? 'Files have been restored to their original location.'
@@ -437,10 +532,64 @@ export default function SelectiveRestore({
                 <div className="status-card" style={{ borderColor: 'var(--danger)' }}>
                     <div style={{ color: 'var(--danger)', fontWeight: 700, marginBottom: 8 }}>❌ Restore failed</div>
                     <p style={{ fontSize: 13, color: 'var(--text-2)' }}>{errMsg}</p>
+                    {failures.length > 0 && (
+                        <ul style={{ fontSize: 12, color: 'var(--text-2)', marginTop: 8, maxHeight: 200, overflowY: 'auto' }}>
+                            {failures.map(f => <li key={f.path}><code>{f.path}</code>: {f.message}</li>)}
+                        </ul>
+                    )}
                     <button className="btn btn-secondary" style={{ marginTop: 16 }}
                         onClick={() => setStatus('idle')}>↩ Back</button>
                 </div>
             )}
+
+            {conflicts && (
+                <div className="modal-overlay" onClick={() => setConflicts(null)}>
+                    <div className="modal" style={{ maxWidth: 800 }} onClick={e => e.stopPropagation()}>
+                        <div className="modal-header">
+                            <div className="modal-title">⚠ {conflicts.length} file(s) changed since the backup</div>
+                            <span className="modal-close" onClick={() => setConflicts(null)}>✕</span>
+                        </div>
+                        <div style={{ fontSize: 13, color: 'var(--text-2)', marginBottom: 10 }}>
+                            These files were edited after the snapshot was taken. Checked files are overwritten, unchecked files are kept.
+                        </div>
+                        <div style={{ maxHeight: 400, overflow: 'auto', marginBottom: 12 }}>
+                            {conflicts.map(c => (
+                                <label key={c.path} style={{ display: 'flex', alignItems: 'center', gap: 8, fontSize: 12, padding: '3px 0' }}>
+                                    <input type="checkbox" checked={overwrite.has(c.path)} onChange={() => toggleOverwrite(c.path)} />
+                                    <span style={{ flex: 1, wordBreak: 'break-all' }}>{c.localPath}</span>
+                                    <span style={{ color: 'var(--text-3)' }}>disk {new Date(c.diskMTime).toLocaleString()} · backup {new Date(c.snapshotMTime).toLocaleString()}</span>
+                                </label>
+                            ))}
+                        </div>
+                        <div className="modal-footer">
+                            <button className="btn btn-ghost" onClick={() => setOverwrite(new Set(conflicts.map(c => c.path)))}>Overwrite all</button>
+                            <button className="btn btn-secondary" onClick={() => setConflicts(null)}>Cancel</button>
+                            <button className="btn btn-primary" onClick={restoreResolved}>Restore</button>
+                        </div>
+                    </div>
+                </div>
+            )}
+
+            {preview && (
+                <div className="modal-overlay" onClick={() => setPreview(null)}>
+                    <div className="modal" style={{ maxWidth: 800 }} onClick={e => e.stopPropagation()}>
+                        <div className="modal-header">
+                            <div className="modal-title" title={preview.path}>{preview.path.split('/').pop()}</div>
+                            <span className="modal-close" onClick={() => setPreview(null)}>✕</span>
+                        </div>
+                        {preview.truncated && (
+                            <div style={{ fontSize: 12, color: 'var(--warning)', marginBottom: 8 }}>⚠ Only the first 1 MB is shown.</div>
+                        )}
+                        {preview.binary ? (
+                            <div style={{ fontSize: 13, color: 'var(--text-3)' }}>Binary file, no preview available.</div>
+                        ) : (
+                            <pre style={{ maxHeight: 500, overflow: 'auto', fontSize: 12, whiteSpace: 'pre-wrap', wordBreak: 'break-all' }}>
+                                {preview.content}
+                            </pre>
+                        )}
+                    </div>
+                </div>
+            )}
         </div>
     );
 }
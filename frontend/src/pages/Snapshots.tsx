@@ -1,12 +1,35 @@
 import React, { useState, useEffect } from 'react';
 import { useToast } from '../ToastContext';
-import { GetRepositories, GetSnapshots, DeleteSnapshot } from '../../wailsjs/go/main/App';
+import { EventsOn, EventsOff } from '../../wailsjs/runtime/runtime';
+import {
+    GetRepositories, GetSnapshots, PreviewDeleteSnapshot, DeleteSnapshot, ApplyRetention, EnrichSnapshots, CancelSnapshotEnrichment,
+    ProtectSnapshot, UnprotectSnapshot, GetTagColors, SetTagColor, SetSnapshotNote,
+    SelectExportFile, ExportSnapshotArchive, ForgetRange, PinBaselineSnapshot, DiffSnapshots, GetSnapshotCalendar, ForgetByTag, TestRestore, SetLastUsedRepo,
+    GetSafeMode
+} from '../../wailsjs/go/main/App';
 
-interface Repo { id: string; name: string; uri: string; }
+interface Repo { id: string; name: string; uri: string; baselineSnapshot?: string; }
 interface Snapshot {
     id: string; short_id: string; time: string;
     hostname: string; username: string;
-    paths: string[]; tags: string[];
+    paths: string[]; tags: string[]; note?: string;
+}
+
+interface Policy { keepLast: number; keepDaily: number; keepWeekly: number; keepMonthly: number; keepYearly: number; }
+interface DiffResult { changes: { path: string; modifier: string }[]; changedFiles: number; added: { files: number; bytes: number }; removed: { files: number; bytes: number }; }
+interface ForgetGroup { host: string; tags: string[]; paths: string[]; keep: Snapshot[] | null; remove: Snapshot[] | null; }
+
+function fmtSize(b: number): string {
+    if (b < 1048576) return (b / 1024).toFixed(1) + ' KB';
+    if (b < 1073741824) return (b / 1048576).toFixed(1) + ' MB';
+    return (b / 1073741824).toFixed(2) + ' GB';
+}
+
+// Tage der Aktivitäts-Heatmap
+const calendarDays = 91;
+
+function dayKey(d: Date) {
+    return `${d.getFullYear()}-${String(d.getMonth() + 1).padStart(2, '0')}-${String(d.getDate()).padStart(2, '0')}`;
 }
 
 function fmtDate(iso: string) {
@@ -21,6 +44,92 @@ export default function Snapshots({ onRestore }: { onRestore: (repoId: string, s
     const [snapshots, setSnapshots] = useState<Snapshot[]>([]);
     const [loading, setLoading] = useState(false);
     const [deleting, setDeleting] = useState<string | null>(null);
+    const [policy, setPolicy] = useState<Policy>({ keepLast: 0, keepDaily: 7, keepWeekly: 4, keepMonthly: 12, keepYearly: 0 });
+    const [preview, setPreview] = useState<ForgetGroup[] | null>(null);
+    const [onlyTag, setOnlyTag] = useState('');
+    const [applying, setApplying] = useState(false);
+    const [sizes, setSizes] = useState<Record<string, number>>({});
+    const [tagColors, setTagColors] = useState<Record<string, string>>({});
+    const [range, setRange] = useState({ host: '', from: '', to: '' });
+    const [rangePreview, setRangePreview] = useState<Snapshot[] | null>(null);
+    const [exporting, setExporting] = useState<{ id: string; bytes: number } | null>(null);
+    const [calendar, setCalendar] = useState<Record<string, number>>({});
+    const [drill, setDrill] = useState<{ id: string; percent: number } | null>(null);
+    const [deletePhase, setDeletePhase] = useState<{ phase: string; percent: number; message: string } | null>(null);
+    const [safeMode, setSafeMode] = useState(false);
+
+    useEffect(() => {
+        GetSafeMode().then(setSafeMode).catch(() => { });
+        return EventsOn('safemode:changed', setSafeMode);
+    }, []);
+
+    useEffect(() => { GetTagColors().then((c: Record<string, string>) => setTagColors(c || {})).catch(() => { }); }, []);
+
+    const pickTagColor = async (tag: string) => {
+        const color = prompt(`Color for tag "${tag}" (#rrggbb, empty to reset):`, tagColors[tag] || '');
+        if (color === null) return;
+        try {
+            await SetTagColor(tag, color.trim());
+            setTagColors(await GetTagColors() || {});
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
+    useEffect(() => {
+        EventsOn('snapshot:enriched', (s: { snapshot_id: string; total_size: number }) =>
+            setSizes(prev => ({ ...prev, [s.snapshot_id]: s.total_size })));
+        return () => { EventsOff('snapshot:enriched'); CancelSnapshotEnrichment(); };
+    }, []);
+
+    useEffect(() => {
+        const onPhase = (p: { phase: string; percent: number; message: string }) => setDeletePhase(p);
+        EventsOn('forget:progress', onPhase);
+        EventsOn('prune:progress', onPhase);
+        return () => { EventsOff('forget:progress'); EventsOff('prune:progress'); };
+    }, []);
+
+    useEffect(() => {
+        EventsOn('export:progress', (p: { bytesWritten: number }) =>
+            setExporting(prev => prev && { ...prev, bytes: p.bytesWritten }));
+        return () => EventsOff('export:progress');
+    }, []);
+
+    useEffect(() => {
+        EventsOn('testrestore:progress', (p: { percent_done: number }) =>
+            setDrill(prev => prev && { ...prev, percent: p.percent_done }));
+        EventsOn('testrestore:complete', (r: { success: boolean; sampled: boolean; files: number; bytes: number; durationSeconds: number; failures: { path: string }[] | null; error?: string }) => {
+            setDrill(null);
+            if (r.success) {
+                addToast({ type: 'success', title: 'Test restore passed', message: `${r.files} files (${fmtSize(r.bytes)}) restored and verified in ${Math.round(r.durationSeconds)}s${r.sampled ? ' (sample)' : ''}` });
+            } else {
+                const failed = (r.failures || []).slice(0, 5).map(f => f.path).join('\n');
+                addToast({ type: 'error', title: 'Test restore failed', message: [r.error, failed].filter(Boolean).join('\n') });
+            }
+        });
+        return () => { EventsOff('testrestore:progress'); EventsOff('testrestore:complete'); };
+    }, []);
+
+    const testRestore = async (snap: Snapshot) => {
+        setDrill({ id: snap.id, percent: 0 });
+        try {
+            await TestRestore(selectedRepo, snap.id);
+        } catch (e: unknown) {
+            setDrill(null);
+            addToast({ type: 'error', title: 'Test restore failed', message: String(e) });
+        }
+    };
+
+    const exportArchive = async (snap: Snapshot) => {
+        const file = await SelectExportFile(`snapshot-${snap.short_id}.zip`);
+        if (!file) return;
+        const format = file.toLowerCase().endsWith('.tar') ? 'tar' : 'zip';
+        setExporting({ id: snap.id, bytes: 0 });
+        try {
+            await ExportSnapshotArchive(selectedRepo, snap.id, '', file, format);
+            addToast({ type: 'success', title: 'Archive exported', message: file });
+        } catch (e: unknown) {
+            addToast({ type: 'error', title: 'Export failed', message: String(e) });
+        } finally { setExporting(null); }
+    };
 
     useEffect(() => {
         GetRepositories().then((r: Repo[]) => {
@@ -36,21 +145,114 @@ export default function Snapshots({ onRestore }: { onRestore: (repoId: string, s
     const load = () => {
         setLoading(true);
         setSnapshots([]);
+        setSizes({});
         GetSnapshots(selectedRepo)
-            .then((s: Snapshot[]) => setSnapshots(s || []))
+            .then((s: Snapshot[]) => {
+                setSnapshots(s || []);
+                if (s?.length) EnrichSnapshots(selectedRepo, s.map(x => x.id)).catch(() => { });
+            })
+            .then(() => GetSnapshotCalendar(selectedRepo, new Date(Date.now() - calendarDays * 86400000).toISOString(), new Date().toISOString()))
+            .then((c: Record<string, number>) => setCalendar(c || {}))
             .catch((e: unknown) => addToast({ type: 'error', title: 'Error', message: String(e) }))
             .finally(() => setLoading(false));
     };
 
     const del = async (snap: Snapshot) => {
-        if (!confirm(`Delete snapshot ${snap.short_id}?\nThis cannot be undone.`)) return;
         setDeleting(snap.id);
         try {
-            await DeleteSnapshot(selectedRepo, snap.id);
+            const preview: { rawSize: number; remaining: number; token: string } = await PreviewDeleteSnapshot(selectedRepo, snap.id);
+            const last = preview.remaining === 0 ? '\n\n⚠ This is the LAST snapshot in this repository.' : '';
+            if (!confirm(`Delete snapshot ${snap.short_id}?\nUp to ${fmtSize(preview.rawSize)} of data will be freed.${last}\n\nThis cannot be undone.`)) return;
+            await DeleteSnapshot(selectedRepo, snap.id, preview.token);
             addToast({ type: 'success', title: `Snapshot ${snap.short_id} deleted` });
             load();
         } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
-        finally { setDeleting(null); }
+        finally { setDeleting(null); setDeletePhase(null); }
+    };
+
+    const editNote = async (snap: Snapshot) => {
+        const note = prompt(`Note for snapshot ${snap.short_id}:`, snap.note || '');
+        if (note === null) return;
+        try {
+            await SetSnapshotNote(selectedRepo, snap.id, note);
+            setSnapshots(prev => prev.map(x => x.id === snap.id ? { ...x, note: note.trim() } : x));
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
+    const baseline = repos.find(r => r.id === selectedRepo)?.baselineSnapshot || '';
+
+    const togglePin = async (snap: Snapshot) => {
+        const id = baseline === snap.id ? '' : snap.id;
+        try {
+            await PinBaselineSnapshot(selectedRepo, id);
+            setRepos(prev => prev.map(r => r.id === selectedRepo ? { ...r, baselineSnapshot: id } : r));
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
+    const compareWithBaseline = async (snap: Snapshot) => {
+        try {
+            const d: DiffResult = await DiffSnapshots(selectedRepo, '', snap.id);
+            addToast({
+                type: 'info', title: `${d.changes.length} change(s) since baseline`,
+                message: `+${d.added.files} files (${fmtSize(d.added.bytes)}), −${d.removed.files} files (${fmtSize(d.removed.bytes)}), ${d.changedFiles} changed`
+            });
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
+    const toggleProtect = async (snap: Snapshot) => {
+        const isProtected = (snap.tags || []).includes('protected');
+        try {
+            if (isProtected) await UnprotectSnapshot(selectedRepo, snap.id);
+            else await ProtectSnapshot(selectedRepo, snap.id);
+            addToast({ type: 'success', title: `Snapshot ${snap.short_id} ${isProtected ? 'unprotected' : 'protected'}` });
+            load();
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+    };
+
+    const previewRetention = async () => {
+        setApplying(true);
+        try {
+            setPreview(await (onlyTag.trim() ? ForgetByTag(selectedRepo, onlyTag, policy, true) : ApplyRetention(selectedRepo, policy, true)) || []);
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+        finally { setApplying(false); }
+    };
+
+    const applyRetention = async () => {
+        setApplying(true);
+        try {
+            const groups: ForgetGroup[] = await (onlyTag.trim() ? ForgetByTag(selectedRepo, onlyTag, policy, false) : ApplyRetention(selectedRepo, policy, false)) || [];
+            // Im Safe-Mode läuft forget nur als Probelauf: Ergebnis als Vorschau zeigen
+            if (safeMode) {
+                setPreview(groups);
+                addToast({ type: 'info', title: 'Safe mode: dry run only', message: 'Nothing was removed. Turn off safe mode to apply the policy.' });
+                return;
+            }
+            addToast({ type: 'success', title: 'Retention policy applied' });
+            setPreview(null);
+            load();
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+        finally { setApplying(false); }
+    };
+
+    const toRemove = (preview || []).flatMap(g => g.remove || []);
+
+    const forgetRange = async (dryRun: boolean) => {
+        if (!range.from || !range.to) { addToast({ type: 'warning', title: 'Please select a date range' }); return; }
+        setApplying(true);
+        try {
+            const removed: Snapshot[] = await ForgetRange(selectedRepo, range.host,
+                new Date(range.from).toISOString(), new Date(range.to).toISOString(), dryRun) || [];
+            if (dryRun) setRangePreview(removed);
+            else if (safeMode) {
+                setRangePreview(removed);
+                addToast({ type: 'info', title: 'Safe mode: dry run only', message: 'Nothing was removed. Turn off safe mode to delete these snapshots.' });
+            } else {
+                addToast({ type: 'success', title: `${removed.length} snapshot(s) removed` });
+                setRangePreview(null);
+                load();
+            }
+        } catch (e: unknown) { addToast({ type: 'error', title: 'Error', message: String(e) }); }
+        finally { setApplying(false); }
     };
 
     return (
@@ -68,12 +270,99 @@ export default function Snapshots({ onRestore }: { onRestore: (repoId: string, s
             <div className="card" style={{ marginBottom: 16 }}>
                 <div className="repo-select-wrap">
                     <label>Repository:</label>
-                    <select value={selectedRepo} onChange={e => setSelectedRepo(e.target.value)} disabled={loading}>
+                    <select value={selectedRepo} onChange={e => { setSelectedRepo(e.target.value); SetLastUsedRepo(e.target.value).catch((err: unknown) => addToast({ type: 'error', title: 'Error', message: String(err) })); }} disabled={loading}>
                         {repos.map(r => <option key={r.id} value={r.id}>{r.name}</option>)}
                     </select>
                 </div>
             </div>
 
+            <div className="card" style={{ marginBottom: 16 }}>
+                <div className="row" style={{ gap: 8, flexWrap: 'wrap' }}>
+                    <label>Keep:</label>
+                    {(['keepLast', 'keepDaily', 'keepWeekly', 'keepMonthly', 'keepYearly'] as (keyof Policy)[]).map(k => (
+                        <label key={k} style={{ fontSize: 12 }}>
+                            {k.replace('keep', '')}{' '}
+                            <input type="number" min={0} style={{ width: 56 }} value={policy[k]}
+                                onChange={e => { setPolicy(p => ({ ...p, [k]: Number(e.target.value) })); setPreview(null); }} />
+                        </label>
+                    ))}
+                    <input placeholder="Only tag (optional)" style={{ width: 140 }} value={onlyTag}
+                        title="Apply the policy only to snapshots with this tag"
+                        onChange={e => { setOnlyTag(e.target.value); setPreview(null); }} />
+                    <button className="btn btn-secondary btn-sm" onClick={previewRetention} disabled={applying || !selectedRepo}>
+                        {applying ? <span className="spinner" /> : '🔍 Preview retention'}
+                    </button>
+                </div>
+                {preview && (
+                    <div style={{ marginTop: 12, fontSize: 13 }}>
+                        {toRemove.length === 0 ? 'No snapshots would be removed.' : (
+                            <>
+                                <div style={{ marginBottom: 8 }}>
+                                    {toRemove.length} snapshot(s) will be removed:{' '}
+                                    {toRemove.map(s => <span key={s.id} className="badge badge-info" style={{ marginRight: 4 }}>{s.short_id} · {fmtDate(s.time)}</span>)}
+                                </div>
+                                <button className="btn btn-danger btn-sm" onClick={applyRetention} disabled={applying}>
+                                    🗑️ Remove {toRemove.length} snapshot(s)
+                                </button>
+                            </>
+                        )}
+                    </div>
+                )}
+            </div>
+
+            <div className="card" style={{ marginBottom: 16 }}>
+                <div className="row" style={{ gap: 8, flexWrap: 'wrap' }}>
+                    <label>Remove range:</label>
+                    <input type="datetime-local" value={range.from}
+                        onChange={e => { setRange(r => ({ ...r, from: e.target.value })); setRangePreview(null); }} />
+                    <span>–</span>
+                    <input type="datetime-local" value={range.to}
+                        onChange={e => { setRange(r => ({ ...r, to: e.target.value })); setRangePreview(null); }} />
+                    <input placeholder="Host (optional)" style={{ width: 140 }} value={range.host}
+                        onChange={e => { setRange(r => ({ ...r, host: e.target.value })); setRangePreview(null); }} />
+                    <button className="btn btn-secondary btn-sm" onClick={() => forgetRange(true)} disabled={applying || !selectedRepo}>
+                        {applying ? <span className="spinner" /> : '🔍 Preview'}
+                    </button>
+                </div>
+                {rangePreview && (
+                    <div style={{ marginTop: 12, fontSize: 13 }}>
+                        {rangePreview.length === 0 ? 'No snapshots in this range.' : (
+                            <>
+                                <div style={{ marginBottom: 8 }}>
+                                    {rangePreview.length} snapshot(s) will be removed:{' '}
+                                    {rangePreview.map(s => <span key={s.id} className="badge badge-info" style={{ marginRight: 4 }}>{s.short_id} · {fmtDate(s.time)}</span>)}
+                                </div>
+                                <button className="btn btn-danger btn-sm" onClick={() => forgetRange(false)} disabled={applying}>
+                                    🗑️ Remove {rangePreview.length} snapshot(s)
+                                </button>
+                            </>
+                        )}
+                    </div>
+                )}
+            </div>
+
+            {Object.keys(calendar).length > 0 && (
+                <div className="card" style={{ marginBottom: 16 }}>
+                    <div style={{ fontSize: 12, color: 'var(--text-3)', marginBottom: 8 }}>Backups in the last {calendarDays} days</div>
+                    <div style={{ display: 'grid', gridTemplateRows: 'repeat(7, 10px)', gridAutoFlow: 'column', gap: 3 }}>
+                        {Array.from({ length: calendarDays }, (_, i) => {
+                            const d = new Date(Date.now() - (calendarDays - 1 - i) * 86400000);
+                            const n = calendar[dayKey(d)] || 0;
+                            return <div key={i} title={`${dayKey(d)}: ${n} snapshot(s)`}
+                                style={{ width: 10, height: 10, borderRadius: 2, background: n ? 'var(--success)' : 'var(--bg-2)', opacity: n ? Math.min(1, 0.4 + n * 0.2) : 1 }} />;
+                        })}
+                    </div>
+                </div>
+            )}
+
+            {deleting && deletePhase && (
+                <div className="card" style={{ marginBottom: 16, fontSize: 13 }}>
+                    <span className="spinner" /> {deletePhase.phase === 'prune' ? 'Pruning unused data' : 'Removing snapshot'}
+                    {deletePhase.percent >= 0 && ` · ${deletePhase.percent.toFixed(0)}%`}
+                    <span style={{ color: 'var(--text-3)' }}> — {deletePhase.message}</span>
+                </div>
+            )}
+
             {loading ? (
                 <div style={{ display: 'flex', flexDirection: 'column', gap: 8 }}>
                     {[1, 2, 3, 4, 5].map(i => <div key={i} className="skeleton" style={{ height: 52, borderRadius: 8 }} />)}
@@ -95,13 +384,19 @@ export default function Snapshots({ onRestore }: { onRestore: (repoId: string, s
                                     <th>Host</th>
                                     <th>Paths</th>
                                     <th>Tags</th>
+                                    <th>Size</th>
                                     <th style={{ textAlign: 'right' }}>Actions</th>
                                 </tr>
                             </thead>
                             <tbody>
                                 {snapshots.map(s => (
                                     <tr key={s.id}>
-                                        <td>{s.short_id}</td>
+                                        <td>
+                                            {s.short_id}
+                                            <span style={{ cursor: 'pointer', marginLeft: 6, opacity: s.note ? 1 : 0.4 }}
+                                                title={s.note ? 'Edit note' : 'Add note'} onClick={() => editNote(s)}>📝</span>
+                                            {s.note && <div style={{ fontSize: 11, color: 'var(--text-3)' }}>{s.note}</div>}
+                                        </td>
                                         <td>{fmtDate(s.time)}</td>
                                         <td style={{ color: 'var(--text-2)' }}>{s.hostname}</td>
                                         <td>
@@ -113,17 +408,42 @@ export default function Snapshots({ onRestore }: { onRestore: (repoId: string, s
                                         </td>
                                         <td>
                                             {(s.tags || []).map(t => (
-                                                <span key={t} className="badge badge-info" style={{ marginRight: 4 }}>{t}</span>
+                                                <span key={t} className="badge badge-info" title="Click to change color"
+                                                    style={{ marginRight: 4, cursor: 'pointer', ...(tagColors[t] ? { background: tagColors[t], color: '#fff' } : {}) }}
+                                                    onClick={() => pickTagColor(t)}>{t}</span>
                                             ))}
                                         </td>
+                                        <td style={{ color: 'var(--text-2)' }}>
+                                            {sizes[s.id] !== undefined ? fmtSize(sizes[s.id]) : '…'}
+                                        </td>
                                         <td>
                                             <div style={{ display: 'flex', gap: 6, justifyContent: 'flex-end' }}>
                                                 <button className="btn btn-secondary btn-sm"
                                                     onClick={() => onRestore(selectedRepo, s.id)}>
                                                     ⬇ Restore
                                                 </button>
+                                                <button className="btn btn-ghost btn-sm" title="Test restore: restore to a temp folder, verify and discard"
+                                                    disabled={drill !== null} onClick={() => testRestore(s)}>
+                                                    {drill?.id === s.id ? <><span className="spinner" />{Math.round(drill.percent * 100)}%</> : '🧪'}
+                                                </button>
+                                                <button className="btn btn-ghost btn-sm" title="Export as tar/zip archive"
+                                                    disabled={exporting !== null} onClick={() => exportArchive(s)}>
+                                                    {exporting?.id === s.id ? <><span className="spinner" />{fmtSize(exporting.bytes)}</> : '📦'}
+                                                </button>
+                                                <button className="btn btn-ghost btn-sm"
+                                                    title={baseline === s.id ? 'Unpin baseline' : 'Pin as comparison baseline'}
+                                                    style={{ opacity: baseline === s.id ? 1 : 0.5 }} onClick={() => togglePin(s)}>📌</button>
+                                                {baseline && baseline !== s.id && (
+                                                    <button className="btn btn-ghost btn-sm" title="Compare with pinned baseline"
+                                                        onClick={() => compareWithBaseline(s)}>Δ</button>
+                                                )}
+                                                <button className="btn btn-ghost btn-sm"
+                                                    title={(s.tags || []).includes('protected') ? 'Unprotect' : 'Protect from deletion'}
+                                                    onClick={() => toggleProtect(s)}>
+                                                    {(s.tags || []).includes('protected') ? '🔒' : '🔓'}
+                                                </button>
                                                 <button className="btn btn-danger btn-sm"
-                                                    disabled={deleting === s.id}
+                                                    disabled={deleting === s.id || (s.tags || []).includes('protected')}
                                                     onClick={() => del(s)}>
                                                     {deleting === s.id ? <span className="spinner" /> : '🗑️'}
                                                 </button>
@@ -2,9 +2,21 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
+	"time"
+
+	"restic-gui/internal/notify"
+)
+
+// config.json contains repository passwords and must only be readable by the owner
+const (
+	dirPerm  os.FileMode = 0700
+	filePerm os.FileMode = 0600
 )
 
 type Repository struct {
@@ -14,29 +26,100 @@ type Repository struct {
 	Password      string   `json:"password"`
 	SourceFolders []string `json:"sourceFolders"`
 	Excludes      []string `json:"excludes"`
+	ExcludeFiles  []string `json:"excludeFiles"` // Dateien für --exclude-file, in dieser Reihenfolge
+	ExtraArgs     []string `json:"extraArgs"`
+	DefaultTags   []string `json:"defaultTags"`
+	BackupRetries int      `json:"backupRetries"` // Wiederholungen bei Netzwerkfehlern
+	Priority      string   `json:"priority"`      // CPU-Priorität für backup/prune: "", "low" oder "idle"
+	CacheDir      string   `json:"cacheDir"`      // eigenes restic-Cache-Verzeichnis, leer = gemeinsamer Standard-Cache
+
+	// Backend-Typ der Eingabemaske: "", "local", "sftp", "rest", "s3", "b2"
+	// oder "azure". URI und Env werden daraus zusammengesetzt (BuildBackend).
+	BackendType string            `json:"backendType"`
+	Env         map[string]string `json:"env"` // z.B. AWS_ACCESS_KEY_ID für s3
+
+	// Shell-Befehle vor/nach dem Backup, z.B. ein Datenbank-Dump
+	PreBackupCommand   string `json:"preBackupCommand"`
+	PostBackupCommand  string `json:"postBackupCommand"`
+	IgnorePreBackupErr bool   `json:"ignorePreBackupErr"` // Backup trotz fehlgeschlagenem Pre-Befehl
+
+	LastCheckTime   time.Time `json:"lastCheckTime"`
+	LastCheckResult string    `json:"lastCheckResult"` // "ok" oder Fehlermeldung
+
+	// Automatische Prüfung: alle CheckIntervalDays Tage (0 = aus), optional mit
+	// --read-data-subset, z.B. "10%"
+	CheckIntervalDays   int    `json:"checkIntervalDays"`
+	CheckReadDataSubset string `json:"checkReadDataSubset"`
+
+	// Größenlimit in Bytes (0 = aus), z.B. für ein kostenloses B2-Kontingent.
+	// Backups werden abgelehnt, wenn das Limit überschritten würde, außer
+	// QuotaWarnOnly ist gesetzt
+	MaxRepoSize   uint64 `json:"maxRepoSize"`
+	QuotaWarnOnly bool   `json:"quotaWarnOnly"`
+
+	ResticRepoID string `json:"resticRepoId"` // ID aus restic cat config, erkennt ein falsches Ziel
+
+	Metadata map[string]string `json:"metadata"` // freie Schlüssel/Werte, z.B. owner, environment, notes
+
+	SnapshotNotes map[string]string `json:"snapshotNotes"` // Snapshot-ID → Notiz; restic kennt keine Notizen
+
+	BaselineSnapshot string `json:"baselineSnapshot"` // angehefteter Snapshot, Standard-Vergleichsbasis für DiffSnapshots
+}
+
+// UIState holds window bounds and navigation state restored on startup
+type UIState struct {
+	WindowX      int    `json:"windowX"`
+	WindowY      int    `json:"windowY"`
+	WindowWidth  int    `json:"windowWidth"`
+	WindowHeight int    `json:"windowHeight"`
+	Maximised    bool   `json:"maximised"`
+	LastTab      string `json:"lastTab"`
+	LastRepo     string `json:"lastRepo"`
 }
 
 type AppConfig struct {
 	Repositories []Repository `json:"repositories"`
 	LastUsedRepo string       `json:"lastUsedRepo"`
+	RecentRepos  []string     `json:"recentRepos"` // zuletzt verwendete Repository-IDs, neueste zuerst
+	UIState      UIState      `json:"uiState"`
+	Language     string       `json:"language"` // z.B. "en", "de"; leer = Englisch
+
+	MaxConcurrentJobs int `json:"maxConcurrentJobs"` // 0 = 1, Backups nacheinander
+	MaxOutputMB       int `json:"maxOutputMB"`       // Ausgabelimit für restic-Befehle, 0 = Standard
+
+	IncludeAppDirs bool `json:"includeAppDirs"` // true = restic-Cache und App-Konfiguration nicht automatisch ausschließen
+
+	Notifications []notify.Target `json:"notifications"` // Ziele für Job-Ergebnisse (E-Mail, Webhook)
+
+	TagColors map[string]string `json:"tagColors"` // Snapshot-Tag → Farbe, z.B. "#e5484d"
+
+	StatsPollMinutes int  `json:"statsPollMinutes"` // Größe/letztes Backup im Hintergrund aktualisieren, 0 = aus
+	PollOnMetered    bool `json:"pollOnMetered"`    // auch auf getakteten Verbindungen abfragen
+
+	CheckResticUpdates bool `json:"checkResticUpdates"` // neueste restic-Version bei GitHub abfragen
+
+	SafeMode bool `json:"safeMode"` // Backups nur als --dry-run, keine löschenden Operationen
 }
 
 type ConfigManager struct {
-	path   string
-	Config AppConfig
-	mu     sync.RWMutex
+	path     string
+	profile  string // Name des aktiven Profils, siehe profiles.go
+	fallback bool   // Konfiguration im Temp-Verzeichnis, siehe configDir
+	Config   AppConfig
+	mu       sync.RWMutex
+	warnings []string
+
+	// Zustand der Datei beim letzten Laden/Speichern, erkennt externe Änderungen
+	modTime time.Time
+	size    int64
+	stop    chan struct{}
 }
 
 func NewConfigManager() (*ConfigManager, error) {
-	appData, err := os.UserConfigDir()
-	if err != nil {
-		appData = os.TempDir()
-	}
-	dir := filepath.Join(appData, "restic-gui")
-	os.MkdirAll(dir, 0755)
-	path := filepath.Join(dir, "config.json")
+	dir, fallback, warnings := configDir()
+	profile := readActiveProfile(dir)
 
-	cm := &ConfigManager{path: path}
+	cm := &ConfigManager{path: profilePath(dir, profile), profile: profile, fallback: fallback, warnings: warnings}
 	if err := cm.Load(); err != nil {
 		cm.Config = AppConfig{Repositories: []Repository{}}
 		cm.Save()
@@ -44,24 +127,159 @@ func NewConfigManager() (*ConfigManager, error) {
 	return cm, nil
 }
 
+// configDirName ist der Ordner der App im Konfigurationsverzeichnis
+const configDirName = "restic-gui"
+
+// configDir returns the first usable config directory: below the user
+// config dir, otherwise below the temp dir. fallback reports that the
+// preferred location could not be used; warnings say why.
+func configDir() (dir string, fallback bool, warnings []string) {
+	if appData, err := os.UserConfigDir(); err != nil {
+		warnings = append(warnings, fmt.Sprintf("user config directory unknown: %v", err))
+	} else {
+		dir = filepath.Join(appData, configDirName)
+		err := ensureWritableDir(dir)
+		if err == nil {
+			return dir, false, warnings
+		}
+		warnings = append(warnings, fmt.Sprintf("%s is not writable: %v", dir, err))
+	}
+	tmp := filepath.Join(os.TempDir(), configDirName)
+	if err := ensureWritableDir(tmp); err != nil {
+		warnings = append(warnings, fmt.Sprintf("%s is not writable: %v; settings will not be saved", tmp, err))
+	} else {
+		warnings = append(warnings, fmt.Sprintf("settings are stored in %s and may be removed by the system", tmp))
+	}
+	return tmp, true, warnings
+}
+
+// ensureWritableDir legt dir an und prüft per Testdatei, ob es beschreibbar ist
+func ensureWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// Dir returns the directory holding config.json and related data files
+func (cm *ConfigManager) Dir() string {
+	return filepath.Dir(cm.path)
+}
+
+// Path returns the config file of the active profile
+func (cm *ConfigManager) Path() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.path
+}
+
+// IsFallback reports whether the config lives in the temp dir because the
+// user config dir could not be used
+func (cm *ConfigManager) IsFallback() bool {
+	return cm.fallback
+}
+
 func (cm *ConfigManager) Load() error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
+	return cm.loadLocked()
+}
+
+// loadLocked reads config.json; cm.mu must be held
+func (cm *ConfigManager) loadLocked() error {
 	data, err := os.ReadFile(cm.path)
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(data, &cm.Config)
+	cm.hardenPermissions()
+	var cfg AppConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+	cm.Config = cfg
+	cm.recordFileState()
+	return nil
 }
 
+// hardenPermissions repairs a config dir or file that is accessible by
+// other users and records a warning for each repaired path.
+// Windows ACLs are not represented by Unix mode bits, so it is a no-op there.
+func (cm *ConfigManager) hardenPermissions() {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	for path, want := range map[string]os.FileMode{filepath.Dir(cm.path): dirPerm, cm.path: filePerm} {
+		info, err := os.Stat(path)
+		if err != nil || info.Mode().Perm()&^want == 0 {
+			continue
+		}
+		if err := os.Chmod(path, want); err != nil {
+			cm.warnings = append(cm.warnings, fmt.Sprintf("%s is accessible by other users (%s) and could not be repaired: %v", path, info.Mode().Perm(), err))
+			continue
+		}
+		cm.warnings = append(cm.warnings, fmt.Sprintf("%s was accessible by other users (%s), permissions reset to %s", path, info.Mode().Perm(), want))
+	}
+}
+
+// Warnings returns the problems found while loading the config
+func (cm *ConfigManager) Warnings() []string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return append([]string(nil), cm.warnings...)
+}
+
+// Save writes the config. If config.json was changed by another program
+// since it was last loaded, the external version is loaded instead and
+// ErrConflict is returned, so changes synced from elsewhere are not clobbered.
 func (cm *ConfigManager) Save() error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
+	if cm.changedExternally() {
+		if err := cm.loadLocked(); err == nil {
+			return ErrConflict
+		}
+	}
+	return cm.saveLocked()
+}
+
+// update applies a single-field change and writes the config. If config.json
+// was changed externally, the external version is loaded first and the change
+// is applied on top of it instead of being dropped. change reports whether
+// anything changed; if not, nothing is written.
+func (cm *ConfigManager) update(change func(cfg *AppConfig) bool) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.changedExternally() {
+		if err := cm.loadLocked(); err != nil {
+			return err
+		}
+	}
+	if !change(&cm.Config) {
+		return nil
+	}
+	return cm.saveLocked()
+}
+
+// saveLocked writes cm.Config to disk; cm.mu must be held
+func (cm *ConfigManager) saveLocked() error {
 	data, err := json.MarshalIndent(cm.Config, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(cm.path, data, 0644)
+	if err := os.WriteFile(cm.path, data, filePerm); err != nil {
+		return err
+	}
+	// WriteFile only applies the mode on creation
+	if err := os.Chmod(cm.path, filePerm); err != nil {
+		return err
+	}
+	cm.recordFileState()
+	return nil
 }
 
 func (cm *ConfigManager) GetRepositories() []Repository {
@@ -90,10 +308,18 @@ func (cm *ConfigManager) AddRepository(repo Repository) error {
 	return cm.Save()
 }
 
+// UpdateRepository replaces the settings of a repository. Fields the app
+// maintains itself (notes, baseline, check result, restic repository ID)
+// have their own setters and are kept from the stored entry, so a stale
+// copy from the frontend cannot revert them.
 func (cm *ConfigManager) UpdateRepository(repo Repository) error {
 	cm.mu.Lock()
 	for i, r := range cm.Config.Repositories {
 		if r.ID == repo.ID {
+			repo.SnapshotNotes = r.SnapshotNotes
+			repo.BaselineSnapshot = r.BaselineSnapshot
+			repo.LastCheckTime, repo.LastCheckResult = r.LastCheckTime, r.LastCheckResult
+			repo.ResticRepoID = r.ResticRepoID
 			cm.Config.Repositories[i] = repo
 			break
 		}
@@ -114,13 +340,332 @@ func (cm *ConfigManager) DeleteRepository(id string) error {
 	if cm.Config.LastUsedRepo == id {
 		cm.Config.LastUsedRepo = ""
 	}
+	cm.Config.RecentRepos = removeString(cm.Config.RecentRepos, id)
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+// SetLastUsedRepo records id as the last used repository. An external change
+// to config.json is loaded and kept; only this field is set on top of it.
+func (cm *ConfigManager) SetLastUsedRepo(id string) error {
+	return cm.update(func(cfg *AppConfig) bool {
+		if cfg.LastUsedRepo == id {
+			return false
+		}
+		cfg.LastUsedRepo = id
+		return true
+	})
+}
+
+// MaxRecentRepos begrenzt die Liste der zuletzt verwendeten Repositories
+const MaxRecentRepos = 5
+
+// TouchRecentRepo moves id to the front of the recently used list. The
+// config is only written when the order actually changes. Like
+// SetLastUsedRepo it survives an external change to config.json.
+func (cm *ConfigManager) TouchRecentRepo(id string) error {
+	return cm.update(func(cfg *AppConfig) bool {
+		if len(cfg.RecentRepos) > 0 && cfg.RecentRepos[0] == id {
+			return false
+		}
+		recent := append([]string{id}, removeString(cfg.RecentRepos, id)...)
+		if len(recent) > MaxRecentRepos {
+			recent = recent[:MaxRecentRepos]
+		}
+		cfg.RecentRepos = recent
+		return true
+	})
+}
+
+// GetRecentRepos returns the recently used repositories, most recent first
+func (cm *ConfigManager) GetRecentRepos() []Repository {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	repos := []Repository{}
+	for _, id := range cm.Config.RecentRepos {
+		for _, r := range cm.Config.Repositories {
+			if r.ID == id {
+				repos = append(repos, r)
+				break
+			}
+		}
+	}
+	return repos
+}
+
+// removeString liefert list ohne s, als neue Slice
+func removeString(list []string, s string) []string {
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (cm *ConfigManager) GetUIState() UIState {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	state := cm.Config.UIState
+	if state.LastRepo == "" {
+		state.LastRepo = cm.Config.LastUsedRepo
+	}
+	return state
+}
+
+func (cm *ConfigManager) SetUIState(state UIState) error {
+	cm.mu.Lock()
+	cm.Config.UIState = state
+	if state.LastRepo != "" {
+		cm.Config.LastUsedRepo = state.LastRepo
+	}
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+func (cm *ConfigManager) GetLanguage() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.Language
+}
+
+func (cm *ConfigManager) SetLanguage(lang string) error {
+	cm.mu.Lock()
+	cm.Config.Language = lang
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+// RenameRepository changes only the name of a repository. Names must be
+// unique, ignoring case, because --run-backup looks repositories up by name.
+func (cm *ConfigManager) RenameRepository(id, name string) error {
+	cm.mu.Lock()
+	index := -1
+	for i, r := range cm.Config.Repositories {
+		if r.ID == id {
+			index = i
+		} else if strings.EqualFold(r.Name, name) {
+			cm.mu.Unlock()
+			return fmt.Errorf("a repository named %q already exists", r.Name)
+		}
+	}
+	if index < 0 {
+		cm.mu.Unlock()
+		return fmt.Errorf("repository not found")
+	}
+	cm.Config.Repositories[index].Name = name
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+// SetCheckResult records the outcome of a "restic check" run
+func (cm *ConfigManager) SetCheckResult(id string, at time.Time, result string) error {
+	cm.mu.Lock()
+	for i, r := range cm.Config.Repositories {
+		if r.ID == id {
+			cm.Config.Repositories[i].LastCheckTime = at
+			cm.Config.Repositories[i].LastCheckResult = result
+			break
+		}
+	}
 	cm.mu.Unlock()
 	return cm.Save()
 }
 
-func (cm *ConfigManager) SetLastUsedRepo(id string) {
+// SetLocation stores the URI of a repository together with the restic
+// repository ID found there
+func (cm *ConfigManager) SetLocation(id, uri, resticRepoID string) error {
 	cm.mu.Lock()
-	cm.Config.LastUsedRepo = id
+	for i, r := range cm.Config.Repositories {
+		if r.ID == id {
+			cm.Config.Repositories[i].URI = uri
+			cm.Config.Repositories[i].ResticRepoID = resticRepoID
+			break
+		}
+	}
 	cm.mu.Unlock()
-	cm.Save()
+	return cm.Save()
+}
+
+func (cm *ConfigManager) GetMaxConcurrentJobs() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.Config.MaxConcurrentJobs < 1 {
+		return 1
+	}
+	return cm.Config.MaxConcurrentJobs
+}
+
+func (cm *ConfigManager) SetMaxConcurrentJobs(n int) error {
+	cm.mu.Lock()
+	cm.Config.MaxConcurrentJobs = n
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+func (cm *ConfigManager) GetIncludeAppDirs() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.IncludeAppDirs
+}
+
+func (cm *ConfigManager) SetIncludeAppDirs(include bool) error {
+	cm.mu.Lock()
+	cm.Config.IncludeAppDirs = include
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+func (cm *ConfigManager) GetCheckResticUpdates() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.CheckResticUpdates
+}
+
+func (cm *ConfigManager) SetCheckResticUpdates(enabled bool) error {
+	cm.mu.Lock()
+	cm.Config.CheckResticUpdates = enabled
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+func (cm *ConfigManager) GetSafeMode() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.SafeMode
+}
+
+func (cm *ConfigManager) SetSafeMode(enabled bool) error {
+	cm.mu.Lock()
+	cm.Config.SafeMode = enabled
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+func (cm *ConfigManager) GetNotifications() []notify.Target {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return append([]notify.Target{}, cm.Config.Notifications...)
+}
+
+func (cm *ConfigManager) SetNotifications(targets []notify.Target) error {
+	cm.mu.Lock()
+	cm.Config.Notifications = targets
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+func (cm *ConfigManager) GetTagColors() map[string]string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	colors := make(map[string]string, len(cm.Config.TagColors))
+	for tag, color := range cm.Config.TagColors {
+		colors[tag] = color
+	}
+	return colors
+}
+
+// SetTagColor assigns a color to a tag; an empty color removes the assignment
+func (cm *ConfigManager) SetTagColor(tag, color string) error {
+	cm.mu.Lock()
+	if color == "" {
+		delete(cm.Config.TagColors, tag)
+	} else {
+		if cm.Config.TagColors == nil {
+			cm.Config.TagColors = map[string]string{}
+		}
+		cm.Config.TagColors[tag] = color
+	}
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+// GetStatsPolling returns the background stats interval (0 = off) and
+// whether polling continues on metered connections
+func (cm *ConfigManager) GetStatsPolling() (int, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.StatsPollMinutes, cm.Config.PollOnMetered
+}
+
+func (cm *ConfigManager) SetStatsPolling(minutes int, onMetered bool) error {
+	cm.mu.Lock()
+	cm.Config.StatsPollMinutes = minutes
+	cm.Config.PollOnMetered = onMetered
+	cm.mu.Unlock()
+	return cm.Save()
+}
+
+// MaxOutputBytes returns the configured output limit, 0 meaning the default
+func (cm *ConfigManager) MaxOutputBytes() int64 {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return int64(cm.Config.MaxOutputMB) << 20
+}
+
+// SetMetadata replaces the metadata of a repository
+func (cm *ConfigManager) SetMetadata(id string, metadata map[string]string) error {
+	cm.mu.Lock()
+	found := false
+	for i, r := range cm.Config.Repositories {
+		if r.ID == id {
+			cm.Config.Repositories[i].Metadata = metadata
+			found = true
+			break
+		}
+	}
+	cm.mu.Unlock()
+	if !found {
+		return fmt.Errorf("repository not found")
+	}
+	return cm.Save()
+}
+
+// SetSnapshotNote stores a note for a snapshot of a repository; an empty
+// note removes it. The map is replaced, not modified, because copies
+// returned by GetRepository share it.
+func (cm *ConfigManager) SetSnapshotNote(id, snapshotID, note string) error {
+	cm.mu.Lock()
+	found := false
+	for i, r := range cm.Config.Repositories {
+		if r.ID == id {
+			notes := make(map[string]string, len(r.SnapshotNotes)+1)
+			for k, v := range r.SnapshotNotes {
+				notes[k] = v
+			}
+			if note == "" {
+				delete(notes, snapshotID)
+			} else {
+				notes[snapshotID] = note
+			}
+			cm.Config.Repositories[i].SnapshotNotes = notes
+			found = true
+			break
+		}
+	}
+	cm.mu.Unlock()
+	if !found {
+		return fmt.Errorf("repository not found")
+	}
+	return cm.Save()
+}
+
+// SetBaselineSnapshot pins the snapshot DiffSnapshots compares against by
+// default; an empty snapshotID removes the pin
+func (cm *ConfigManager) SetBaselineSnapshot(id, snapshotID string) error {
+	cm.mu.Lock()
+	found := false
+	for i, r := range cm.Config.Repositories {
+		if r.ID == id {
+			cm.Config.Repositories[i].BaselineSnapshot = snapshotID
+			found = true
+			break
+		}
+	}
+	cm.mu.Unlock()
+	if !found {
+		return fmt.Errorf("repository not found")
+	}
+	return cm.Save()
 }
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultProfile is stored in config.json; other profiles in config-<name>.json
+const DefaultProfile = "default"
+
+// activeProfileFile merkt sich das zuletzt gewählte Profil
+const activeProfileFile = "active-profile"
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// profilePath liefert die Konfigurationsdatei eines Profils
+func profilePath(dir, name string) string {
+	if name == DefaultProfile {
+		return filepath.Join(dir, "config.json")
+	}
+	return filepath.Join(dir, "config-"+name+".json")
+}
+
+// readActiveProfile liest das gemerkte Profil; unbekannt oder ungültig = Standard
+func readActiveProfile(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, activeProfileFile))
+	name := strings.TrimSpace(string(data))
+	if err != nil || !profileName.MatchString(name) {
+		return DefaultProfile
+	}
+	return name
+}
+
+// Profile returns the name of the active profile
+func (cm *ConfigManager) Profile() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.profile
+}
+
+// ListProfiles returns all profiles in the config dir, sorted, starting with
+// the default profile
+func (cm *ConfigManager) ListProfiles() []string {
+	files, _ := filepath.Glob(filepath.Join(cm.Dir(), "config-*.json"))
+	var names []string
+	for _, f := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(f), "config-"), ".json")
+		if profileName.MatchString(name) && name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// SwitchProfile loads another profile, creating it empty if it does not
+// exist yet, and remembers it for the next start
+func (cm *ConfigManager) SwitchProfile(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (letters, digits, - and _ only)", name)
+	}
+	dir := cm.Dir()
+
+	cm.mu.Lock()
+	prevPath, prevProfile, prevConfig := cm.path, cm.profile, cm.Config
+	cm.path, cm.profile = profilePath(dir, name), name
+	cm.modTime, cm.size = time.Time{}, 0
+	err := cm.loadLocked()
+	cm.mu.Unlock()
+	if os.IsNotExist(err) {
+		cm.mu.Lock()
+		cm.Config = AppConfig{Repositories: []Repository{}}
+		cm.mu.Unlock()
+		err = cm.Save()
+	}
+	if err != nil {
+		// beim alten Profil bleiben
+		cm.mu.Lock()
+		cm.path, cm.profile, cm.Config = prevPath, prevProfile, prevConfig
+		cm.recordFileState()
+		cm.mu.Unlock()
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, activeProfileFile), []byte(name), filePerm)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrConflict is returned by Save when config.json was modified externally.
+// The external version has been loaded; the change must be repeated.
+var ErrConflict = errors.New("config.json was changed by another program and has been reloaded; please repeat your change")
+
+// recordFileState remembers mtime and size of config.json; cm.mu must be held
+func (cm *ConfigManager) recordFileState() {
+	if info, err := os.Stat(cm.path); err == nil {
+		cm.modTime, cm.size = info.ModTime(), info.Size()
+	}
+}
+
+// changedExternally reports whether config.json differs from the last
+// load or save; cm.mu must be held
+func (cm *ConfigManager) changedExternally() bool {
+	info, err := os.Stat(cm.path)
+	if err != nil || cm.modTime.IsZero() {
+		return false
+	}
+	return !info.ModTime().Equal(cm.modTime) || info.Size() != cm.size
+}
+
+// Watch polls config.json every interval and reloads it when another
+// program (e.g. a cloud sync client) changed it, then calls onReload.
+// Polling is used instead of filesystem notifications because synced
+// folders often replace the file rather than writing to it.
+func (cm *ConfigManager) Watch(interval time.Duration, onReload func()) {
+	cm.mu.Lock()
+	if cm.stop != nil {
+		cm.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	cm.stop = stop
+	cm.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				cm.mu.Lock()
+				reloaded := cm.changedExternally() && cm.loadLocked() == nil
+				cm.mu.Unlock()
+				if reloaded && onReload != nil {
+					onReload()
+				}
+			}
+		}
+	}()
+}
+
+// StopWatching ends a Watch started earlier
+func (cm *ConfigManager) StopWatching() {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.stop != nil {
+		close(cm.stop)
+		cm.stop = nil
+	}
+}
@@ -0,0 +1,97 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxEntries is how many entries a Log keeps before dropping the oldest
+const maxEntries = 500
+
+// RestoreEntry records a single restore operation
+type RestoreEntry struct {
+	Time       time.Time `json:"time"`
+	RepoID     string    `json:"repoId"`
+	RepoName   string    `json:"repoName"`
+	SnapshotID string    `json:"snapshotId"`
+	Paths      []string  `json:"paths"` // leer = kompletter Snapshot
+	Target     string    `json:"target"`
+	ToOriginal bool      `json:"toOriginal"`
+	Verify     bool      `json:"verify,omitempty"` // Inhalte nach dem Schreiben mit dem Snapshot verglichen
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// BackupEntry records a single backup run
+type BackupEntry struct {
+	Time       time.Time   `json:"time"`
+	RepoID     string      `json:"repoId"`
+	RepoName   string      `json:"repoName"`
+	SnapshotID string      `json:"snapshotId,omitempty"`
+	DryRun     bool        `json:"dryRun,omitempty"`
+	Success    bool        `json:"success"`
+	Error      string      `json:"error,omitempty"`
+	ErrorCount int         `json:"errorCount"`           // Dateien, die restic nicht lesen konnte
+	FileErrors []FileError `json:"fileErrors,omitempty"` // die ersten davon, begrenzt
+
+	// aus der summary-Meldung von restic
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+	BytesProcessed  uint64  `json:"bytesProcessed,omitempty"`
+	BytesPerSecond  float64 `json:"bytesPerSecond,omitempty"`
+	DataAdded       uint64  `json:"dataAdded,omitempty"`
+}
+
+// FileError is a file restic could not process
+type FileError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Log is a rotating, file-backed list of entries, newest last
+type Log[T any] struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLog returns a log stored in dir/name
+func NewLog[T any](dir, name string) *Log[T] {
+	return &Log[T]{path: filepath.Join(dir, name)}
+}
+
+// Add appends an entry and drops the oldest ones beyond maxEntries
+func (l *Log[T]) Add(entry T) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := l.load()
+	entries = append(entries, entry)
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, data, 0600)
+}
+
+// List returns all entries, oldest first
+func (l *Log[T]) List() []T {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.load()
+}
+
+func (l *Log[T]) load() []T {
+	var entries []T
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return []T{}
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return []T{}
+	}
+	return entries
+}
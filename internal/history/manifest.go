@@ -0,0 +1,124 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Manifest beschreibt eine laufende oder abgebrochene Wiederherstellung,
+// damit sie nach einer Unterbrechung fortgesetzt werden kann
+type Manifest struct {
+	ID      string       `json:"id"`
+	Started time.Time    `json:"started"`
+	Updated time.Time    `json:"updated"`
+	Restore RestoreEntry `json:"restore"` // Snapshot, Pfade und Ziel
+	Flatten bool         `json:"flatten"`
+	TempDir string       `json:"tempDir,omitempty"` // Zwischenordner bei Flatten/ToOriginal, bleibt bis zum Erfolg erhalten
+
+	// Zuletzt gemeldeter Fortschritt
+	FilesDone  uint64 `json:"filesDone"`
+	TotalFiles uint64 `json:"totalFiles"`
+	BytesDone  uint64 `json:"bytesDone"`
+	TotalBytes uint64 `json:"totalBytes"`
+}
+
+// ManifestStore keeps one JSON file per manifest in a directory
+type ManifestStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewManifestStore returns a store below dir; the directory is created on first save
+func NewManifestStore(dir string) *ManifestStore {
+	return &ManifestStore{dir: dir}
+}
+
+func (s *ManifestStore) path(id string) (string, error) {
+	// IDs kommen aus der UI: keine Pfadbestandteile zulassen
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return "", fmt.Errorf("invalid manifest id")
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// Save writes m, replacing an earlier version
+func (s *ManifestStore) Save(m Manifest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, err := s.path(m.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Get loads a single manifest
+func (s *ManifestStore) Get(id string) (Manifest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var m Manifest
+	path, err := s.path(id)
+	if err != nil {
+		return m, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, fmt.Errorf("restore manifest not found")
+		}
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("failed to parse restore manifest")
+	}
+	return m, nil
+}
+
+// List returns all manifests, newest first; unreadable files are skipped
+func (s *ManifestStore) List() []Manifest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifests := []Manifest{}
+	files, _ := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var m Manifest
+		if json.Unmarshal(data, &m) == nil {
+			manifests = append(manifests, m)
+		}
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].Started.After(manifests[j].Started)
+	})
+	return manifests
+}
+
+// Delete removes a manifest; a missing one is not an error
+func (s *ManifestStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// maxOutput begrenzt die gespeicherte Ausgabe eines Hooks
+const maxOutput = 64 << 10
+
+// Run executes command through the system shell (cmd /C on Windows, sh -c
+// elsewhere) and returns its combined output. env is added to the process
+// environment. The command is killed when ctx is cancelled.
+func Run(ctx context.Context, command string, env []string) (string, error) {
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), env...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	output := out.String()
+	if len(output) > maxOutput {
+		output = output[len(output)-maxOutput:] // das Ende enthält meist den Fehler
+	}
+	output = strings.TrimSpace(output)
+	if err != nil {
+		if ctx.Err() != nil {
+			return output, ctx.Err()
+		}
+		return output, fmt.Errorf("%s: %w", command, err)
+	}
+	return output, nil
+}
@@ -0,0 +1,13 @@
+package hooks
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd", "/C", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd
+}
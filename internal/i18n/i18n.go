@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fallback is used for keys missing in the active language
+const Fallback = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	mu       sync.RWMutex
+	active   = Fallback
+	catalogs = loadCatalogs()
+)
+
+func loadCatalogs() map[string]map[string]string {
+	result := map[string]map[string]string{}
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+	for _, e := range entries {
+		data, err := localeFS.ReadFile(path.Join("locales", e.Name()))
+		if err != nil {
+			panic("i18n: " + err.Error())
+		}
+		var msgs map[string]string
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			panic("i18n: invalid " + e.Name() + ": " + err.Error())
+		}
+		result[strings.TrimSuffix(e.Name(), ".json")] = msgs
+	}
+	return result
+}
+
+// Languages returns the codes of all bundled locales
+func Languages() []string {
+	var langs []string
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// SetLanguage selects the active locale; unknown codes fall back to English
+func SetLanguage(lang string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := catalogs[lang]; ok {
+		active = lang
+	} else {
+		active = Fallback
+	}
+}
+
+// Language returns the active locale code
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// T returns the message for key in the active language, falling back to
+// English and finally to the key itself
+func T(key string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if msg, ok := catalogs[active][key]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[Fallback][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// All returns every message of the active language merged over English,
+// for use by the frontend
+func All() map[string]string {
+	mu.RLock()
+	defer mu.RUnlock()
+	result := make(map[string]string, len(catalogs[Fallback]))
+	for k, v := range catalogs[Fallback] {
+		result[k] = v
+	}
+	for k, v := range catalogs[active] {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,238 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// State of a job in the queue
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateDone      = "done"
+	StateFailed    = "failed"
+	StateCancelled = "cancelled"
+)
+
+// Info is the externally visible snapshot of a job
+type Info struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"` // z.B. "backup"
+	RepoID     string    `json:"repoId"`
+	State      string    `json:"state"`
+	Error      string    `json:"error,omitempty"`
+	QueuedAt   time.Time `json:"queuedAt"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// Func does the actual work of a job; it must stop when ctx is cancelled
+type Func func(ctx context.Context, jobID string) error
+
+// Notify is called on every state change with "job:queued", "job:started"
+// or "job:finished"
+type Notify func(event string, job Info)
+
+type job struct {
+	info   Info
+	fn     Func
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     *sync.WaitGroup // von SubmitTracked, sonst nil
+}
+
+// release meldet den Job bei seiner WaitGroup ab
+func (j *job) release() {
+	if j.wg != nil {
+		j.wg.Done()
+	}
+}
+
+// Queue runs submitted jobs with at most limit of them at the same time.
+// A limit of 1 serializes all jobs.
+type Queue struct {
+	mu       sync.Mutex
+	limit    int
+	pending  []*job
+	running  map[string]*job
+	finished []Info
+	notify   Notify
+}
+
+// maxFinished is how many finished jobs List keeps reporting
+const maxFinished = 50
+
+// NewQueue creates a queue; limit < 1 is treated as 1
+func NewQueue(limit int, notify Notify) *Queue {
+	if limit < 1 {
+		limit = 1
+	}
+	if notify == nil {
+		notify = func(string, Info) {}
+	}
+	return &Queue{limit: limit, running: map[string]*job{}, notify: notify}
+}
+
+// SetLimit changes the concurrency limit; queued jobs start immediately if
+// the new limit allows it
+func (q *Queue) SetLimit(limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+	q.mu.Lock()
+	q.limit = limit
+	q.mu.Unlock()
+	q.schedule()
+}
+
+// Limit returns the concurrency limit
+func (q *Queue) Limit() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.limit
+}
+
+// Submit queues fn and returns the new job's ID
+func (q *Queue) Submit(kind, repoID string, fn Func) string {
+	return q.SubmitTracked(kind, repoID, nil, fn)
+}
+
+// SubmitTracked is Submit with wg counting the job: wg.Add(1) happens before
+// the job is queued and wg.Done once it has finished or was cancelled before
+// it started, so wg.Wait never misses a job that is about to start.
+func (q *Queue) SubmitTracked(kind, repoID string, wg *sync.WaitGroup, fn Func) string {
+	if wg != nil {
+		wg.Add(1)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		info: Info{
+			ID:       uuid.New().String(),
+			Kind:     kind,
+			RepoID:   repoID,
+			State:    StateQueued,
+			QueuedAt: time.Now(),
+		},
+		fn:     fn,
+		ctx:    ctx,
+		cancel: cancel,
+		wg:     wg,
+	}
+	q.mu.Lock()
+	q.pending = append(q.pending, j)
+	info := j.info
+	q.mu.Unlock()
+
+	q.notify("job:queued", info)
+	q.schedule()
+	return info.ID
+}
+
+// schedule starts pending jobs while there is free capacity
+func (q *Queue) schedule() {
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 || len(q.running) >= q.limit {
+			q.mu.Unlock()
+			return
+		}
+		j := q.pending[0]
+		q.pending = q.pending[1:]
+		j.info.State = StateRunning
+		j.info.StartedAt = time.Now()
+		q.running[j.info.ID] = j
+		info := j.info
+		q.mu.Unlock()
+
+		q.notify("job:started", info)
+		go q.run(j)
+	}
+}
+
+func (q *Queue) run(j *job) {
+	err := j.fn(j.ctx, j.info.ID)
+	cancelled := j.ctx.Err() != nil
+	j.cancel()
+
+	q.mu.Lock()
+	delete(q.running, j.info.ID)
+	q.finish(j, err, cancelled)
+	info := j.info
+	q.mu.Unlock()
+
+	q.notify("job:finished", info)
+	j.release()
+	q.schedule()
+}
+
+// finish records the outcome; q.mu must be held
+func (q *Queue) finish(j *job, err error, cancelled bool) {
+	j.info.FinishedAt = time.Now()
+	switch {
+	case cancelled:
+		j.info.State = StateCancelled
+	case err != nil:
+		j.info.State = StateFailed
+		j.info.Error = err.Error()
+	default:
+		j.info.State = StateDone
+	}
+	q.finished = append(q.finished, j.info)
+	if len(q.finished) > maxFinished {
+		q.finished = q.finished[len(q.finished)-maxFinished:]
+	}
+}
+
+// ErrUnknownJob is returned by Cancel for IDs not queued or running
+var ErrUnknownJob = errors.New("job not found")
+
+// Cancel stops a running job or removes it from the queue
+func (q *Queue) Cancel(id string) error {
+	q.mu.Lock()
+	if j, ok := q.running[id]; ok {
+		q.mu.Unlock()
+		j.cancel()
+		return nil
+	}
+	for i, j := range q.pending {
+		if j.info.ID == id {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			j.cancel()
+			q.finish(j, nil, true)
+			info := j.info
+			q.mu.Unlock()
+			q.notify("job:finished", info)
+			j.release()
+			return nil
+		}
+	}
+	q.mu.Unlock()
+	return ErrUnknownJob
+}
+
+// CancelKind cancels all queued and running jobs of a kind ("" = all kinds)
+func (q *Queue) CancelKind(kind string) {
+	for _, info := range q.List() {
+		if (kind == "" || info.Kind == kind) && (info.State == StateQueued || info.State == StateRunning) {
+			q.Cancel(info.ID)
+		}
+	}
+}
+
+// List returns running, queued and recently finished jobs
+func (q *Queue) List() []Info {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	list := make([]Info, 0, len(q.running)+len(q.pending)+len(q.finished))
+	for _, j := range q.running {
+		list = append(list, j.info)
+	}
+	for _, j := range q.pending {
+		list = append(list, j.info)
+	}
+	return append(list, q.finished...)
+}
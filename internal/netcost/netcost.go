@@ -0,0 +1,8 @@
+// Package netcost erkennt getaktete (volumenbegrenzte) Netzwerkverbindungen.
+package netcost
+
+// Metered reports whether the current internet connection is metered
+// (e.g. a mobile hotspot). Unknown counts as not metered.
+func Metered() bool {
+	return metered()
+}
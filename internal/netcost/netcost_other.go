@@ -0,0 +1,23 @@
+//go:build !windows
+
+package netcost
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// metered fragt NetworkManager; ohne nmcli gilt die Verbindung als nicht getaktet
+func metered() bool {
+	out, err := exec.Command("nmcli", "-t", "-f", "GENERAL.METERED", "device", "show").Output()
+	if err != nil {
+		return false
+	}
+	// Eine Zeile je Gerät: "GENERAL.METERED:yes", "…:yes (guessed)", "…:no" oder "…:unknown"
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(strings.TrimPrefix(line, "GENERAL.METERED:"), "yes") {
+			return true
+		}
+	}
+	return false
+}
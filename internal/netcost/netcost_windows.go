@@ -0,0 +1,27 @@
+package netcost
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// costQuery fragt die Verbindungskosten über die WinRT-API ab
+const costQuery = `[void][Windows.Networking.Connectivity.NetworkInformation,Windows.Networking.Connectivity,ContentType=WindowsRuntime];` +
+	`$p=[Windows.Networking.Connectivity.NetworkInformation]::GetInternetConnectionProfile();` +
+	`if($p){$p.GetConnectionCost().NetworkCostType}`
+
+func metered() bool {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", costQuery)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	// "Unrestricted", "Fixed", "Variable" oder "Unknown"
+	switch strings.TrimSpace(string(out)) {
+	case "Fixed", "Variable":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Target ist ein Ziel für Benachrichtigungen: "webhook" oder "smtp"
+type Target struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	OnlyFailures bool   `json:"onlyFailures"`
+
+	// Webhook: POST an URL; ohne Template wird das Event als JSON gesendet.
+	// Template ist ein text/template, das JSON ergeben muss. Werte werden
+	// nicht escaped: Texte mit der Funktion json einsetzen, die sie samt
+	// Anführungszeichen kodiert, z.B. {"text":{{json .Summary}}}
+	URL      string `json:"url"`
+	Template string `json:"template"`
+
+	// SMTP
+	Host     string   `json:"host"`
+	Port     int      `json:"port"` // 0 = 587
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// Event beschreibt das Ergebnis eines Jobs
+type Event struct {
+	Kind    string    `json:"kind"`   // "backup", "restore" oder "check"
+	Repo    string    `json:"repo"`   // Name des Repositories
+	Status  string    `json:"status"` // "success" oder "failure"
+	Summary string    `json:"summary"`
+	Time    time.Time `json:"time"`
+}
+
+// Failed reports whether e describes a failed job
+func (e Event) Failed() bool {
+	return e.Status != "success"
+}
+
+// maxAttempts und retryDelay steuern Wiederholungen bei vorübergehenden Fehlern
+const (
+	maxAttempts = 3
+	retryDelay  = 5 * time.Second
+)
+
+// Validate checks that a target has the fields its type needs
+func (t Target) Validate() error {
+	switch t.Type {
+	case "webhook":
+		if !strings.HasPrefix(t.URL, "http://") && !strings.HasPrefix(t.URL, "https://") {
+			return fmt.Errorf("webhook URL must start with http:// or https://")
+		}
+		if t.Template != "" {
+			// Probelauf mit Anführungszeichen und Zeilenumbruch, wie sie in
+			// restic-Fehlermeldungen vorkommen
+			body, err := renderTemplate(t.Template, Event{
+				Kind: "backup", Repo: `repo "test"`, Status: "failure",
+				Summary: "Fatal: unable to open \"config\"\nsecond line", Time: time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+			if !json.Valid(body) {
+				return fmt.Errorf(`webhook template does not produce valid JSON; insert text with {{json .Summary}}`)
+			}
+		}
+	case "smtp":
+		if t.Host == "" || t.From == "" || len(t.To) == 0 {
+			return fmt.Errorf("SMTP needs host, sender and at least one recipient")
+		}
+	default:
+		return fmt.Errorf("unknown notification type %q", t.Type)
+	}
+	return nil
+}
+
+// Send delivers e to t, retrying transient failures
+func Send(ctx context.Context, t Target, e Event) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		switch t.Type {
+		case "webhook":
+			err = sendWebhook(ctx, t, e)
+		case "smtp":
+			err = sendMail(t, e)
+		default:
+			return fmt.Errorf("unknown notification type %q", t.Type)
+		}
+		if err == nil || attempt >= maxAttempts || !isTransient(err) {
+			return err
+		}
+		select {
+		case <-time.After(retryDelay * time.Duration(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// httpStatusError ist eine Antwort des Webhooks mit Fehlerstatus
+type httpStatusError struct{ code int }
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("webhook returned HTTP %d", e.code)
+}
+
+// isTransient: Netzwerkfehler, HTTP 5xx/429 und SMTP 4xx lohnen einen neuen Versuch
+func isTransient(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500 || statusErr.code == http.StatusTooManyRequests
+	}
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// templateFuncs: json kodiert einen Wert als JSON, damit Anführungszeichen
+// und Zeilenumbrüche in Texten das Ergebnis nicht ungültig machen
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// renderTemplate führt das Webhook-Template für e aus
+func renderTemplate(text string, e Event) ([]byte, error) {
+	tmpl, err := template.New("payload").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e); err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func sendWebhook(ctx context.Context, t Target, e Event) error {
+	var body []byte
+	if t.Template != "" {
+		var err error
+		if body, err = renderTemplate(t.Template, e); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if body, err = json.Marshal(e); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return &httpStatusError{code: resp.StatusCode}
+	}
+	return nil
+}
+
+func sendMail(t Target, e Event) error {
+	port := t.Port
+	if port == 0 {
+		port = 587
+	}
+	var auth smtp.Auth
+	if t.Username != "" {
+		auth = smtp.PlainAuth("", t.Username, t.Password, t.Host)
+	}
+	subject := fmt.Sprintf("[Restic] %s %s: %s", e.Kind, e.Status, e.Repo)
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", t.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(t.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "Repository: %s\r\nJob: %s\r\nStatus: %s\r\nTime: %s\r\n\r\n%s\r\n",
+		e.Repo, e.Kind, e.Status, e.Time.Format(time.RFC1123), e.Summary)
+	// SendMail nutzt STARTTLS, wenn der Server es anbietet
+	return smtp.SendMail(net.JoinHostPort(t.Host, strconv.Itoa(port)), auth, t.From, t.To, []byte(msg.String()))
+}
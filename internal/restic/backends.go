@@ -0,0 +1,181 @@
+package restic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Backend types for BackendParams.Type
+const (
+	BackendLocal = "local"
+	BackendSFTP  = "sftp"
+	BackendREST  = "rest"
+	BackendS3    = "s3"
+	BackendB2    = "b2"
+	BackendAzure = "azure"
+)
+
+// defaultS3Endpoint wird verwendet, wenn kein Endpoint angegeben ist
+const defaultS3Endpoint = "s3.amazonaws.com"
+
+// BackendParams sind die Felder der Eingabemaske je Backend. Nicht jedes
+// Backend nutzt jedes Feld:
+//
+//	local: Path
+//	sftp:  User, Host, Path
+//	rest:  Endpoint (URL), Path
+//	s3:    Endpoint, Region, Bucket, Prefix, AccessKey, SecretKey
+//	b2:    Bucket, Prefix, AccountID, AccountKey
+//	azure: Bucket (Container), Prefix, AccountName, AccountKey
+type BackendParams struct {
+	Type        string `json:"type"`
+	Path        string `json:"path"`
+	User        string `json:"user"`
+	Host        string `json:"host"`
+	Endpoint    string `json:"endpoint"`
+	Region      string `json:"region"`
+	Bucket      string `json:"bucket"`
+	Prefix      string `json:"prefix"`
+	AccessKey   string `json:"accessKey"`
+	SecretKey   string `json:"secretKey"`
+	AccountID   string `json:"accountId"`
+	AccountName string `json:"accountName"`
+	AccountKey  string `json:"accountKey"`
+}
+
+// Build assembles the repository URI and the environment variables restic
+// expects for the backend
+func (p BackendParams) Build() (string, map[string]string, error) {
+	env := map[string]string{}
+	prefix := strings.Trim(p.Prefix, "/")
+	required := func(fields ...string) error {
+		for i := 0; i < len(fields); i += 2 {
+			if strings.TrimSpace(fields[i+1]) == "" {
+				return fmt.Errorf("%s is required", fields[i])
+			}
+		}
+		return nil
+	}
+	switch p.Type {
+	case BackendLocal:
+		if err := required("path", p.Path); err != nil {
+			return "", nil, err
+		}
+		return p.Path, env, nil
+	case BackendSFTP:
+		if err := required("host", p.Host, "path", p.Path); err != nil {
+			return "", nil, err
+		}
+		host := p.Host
+		if p.User != "" {
+			host = p.User + "@" + host
+		}
+		return "sftp:" + host + ":" + p.Path, env, nil
+	case BackendREST:
+		if err := required("URL", p.Endpoint); err != nil {
+			return "", nil, err
+		}
+		uri := strings.TrimSuffix(p.Endpoint, "/")
+		if path := strings.Trim(p.Path, "/"); path != "" {
+			uri += "/" + path
+		}
+		return "rest:" + uri + "/", env, nil
+	case BackendS3:
+		if err := required("bucket", p.Bucket, "access key", p.AccessKey, "secret key", p.SecretKey); err != nil {
+			return "", nil, err
+		}
+		endpoint := strings.TrimSuffix(p.Endpoint, "/")
+		if endpoint == "" {
+			endpoint = defaultS3Endpoint
+		}
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "https://" + endpoint
+		}
+		uri := "s3:" + endpoint + "/" + p.Bucket
+		if prefix != "" {
+			uri += "/" + prefix
+		}
+		env["AWS_ACCESS_KEY_ID"] = p.AccessKey
+		env["AWS_SECRET_ACCESS_KEY"] = p.SecretKey
+		if p.Region != "" {
+			env["AWS_DEFAULT_REGION"] = p.Region
+		}
+		return uri, env, nil
+	case BackendB2:
+		if err := required("bucket", p.Bucket, "account ID", p.AccountID, "account key", p.AccountKey); err != nil {
+			return "", nil, err
+		}
+		env["B2_ACCOUNT_ID"] = p.AccountID
+		env["B2_ACCOUNT_KEY"] = p.AccountKey
+		return "b2:" + p.Bucket + ":" + prefix, env, nil
+	case BackendAzure:
+		if err := required("container", p.Bucket, "account name", p.AccountName, "account key", p.AccountKey); err != nil {
+			return "", nil, err
+		}
+		env["AZURE_ACCOUNT_NAME"] = p.AccountName
+		env["AZURE_ACCOUNT_KEY"] = p.AccountKey
+		return "azure:" + p.Bucket + ":/" + prefix, env, nil
+	}
+	return "", nil, fmt.Errorf("unknown backend type %q", p.Type)
+}
+
+// ParseBackend is the reverse of Build: it fills the form fields from an
+// existing URI and environment, so a saved repository can be edited in the
+// structured form again. Unknown formats come back as BackendLocal with
+// the URI as path.
+func ParseBackend(backendType, uri string, env map[string]string) BackendParams {
+	p := BackendParams{Type: backendType}
+	scheme, rest, _ := strings.Cut(uri, ":")
+	switch {
+	case backendType == BackendSFTP && scheme == "sftp":
+		host, path, _ := strings.Cut(rest, ":")
+		if user, h, ok := strings.Cut(host, "@"); ok {
+			p.User, host = user, h
+		}
+		p.Host, p.Path = host, path
+	case backendType == BackendREST && scheme == "rest":
+		p.Endpoint = strings.TrimSuffix(rest, "/")
+	case backendType == BackendS3 && scheme == "s3":
+		endpoint := rest
+		if i := strings.Index(endpoint, "://"); i >= 0 {
+			endpoint = endpoint[i+3:]
+		}
+		parts := strings.SplitN(endpoint, "/", 3)
+		p.Endpoint = parts[0]
+		if len(parts) > 1 {
+			p.Bucket = parts[1]
+		}
+		if len(parts) > 2 {
+			p.Prefix = parts[2]
+		}
+		p.AccessKey, p.SecretKey, p.Region = env["AWS_ACCESS_KEY_ID"], env["AWS_SECRET_ACCESS_KEY"], env["AWS_DEFAULT_REGION"]
+	case backendType == BackendB2 && scheme == "b2":
+		p.Bucket, p.Prefix, _ = strings.Cut(rest, ":")
+		p.AccountID, p.AccountKey = env["B2_ACCOUNT_ID"], env["B2_ACCOUNT_KEY"]
+	case backendType == BackendAzure && scheme == "azure":
+		container, prefix, _ := strings.Cut(rest, ":")
+		p.Bucket, p.Prefix = container, strings.TrimPrefix(prefix, "/")
+		p.AccountName, p.AccountKey = env["AZURE_ACCOUNT_NAME"], env["AZURE_ACCOUNT_KEY"]
+	default:
+		p.Type, p.Path = BackendLocal, uri
+	}
+	return p
+}
+
+// deniedEnv setzt die App selbst aus URI und Passwort
+var deniedEnv = []string{"RESTIC_REPOSITORY", "RESTIC_PASSWORD", "RESTIC_FROM_"}
+
+// ValidateEnv rejects malformed names and variables the app sets itself
+func ValidateEnv(env map[string]string) error {
+	for name := range env {
+		if name == "" || strings.ContainsAny(name, "= ") {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+		for _, denied := range deniedEnv {
+			if strings.HasPrefix(strings.ToUpper(name), denied) {
+				return fmt.Errorf("environment variable not allowed: %s", name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package restic
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionInfo beschreibt die installierte restic-Version
+type VersionInfo struct {
+	Version   string `json:"version"` // z.B. "0.17.3"
+	GoVersion string `json:"go_version"`
+	GoOS      string `json:"go_os"`
+	GoArch    string `json:"go_arch"`
+}
+
+// String renders the version like restic's own text output
+func (v VersionInfo) String() string {
+	return fmt.Sprintf("restic %s compiled with %s on %s/%s", v.Version, v.GoVersion, v.GoOS, v.GoArch)
+}
+
+// AtLeast reports whether the version is major.minor.patch or newer;
+// unparsable versions (e.g. development builds) count as new enough
+func (v VersionInfo) AtLeast(major, minor, patch int) bool {
+	have, ok := parseVersion(v.Version)
+	if !ok {
+		return true
+	}
+	want := [3]int{major, minor, patch}
+	for i := range have {
+		if have[i] != want[i] {
+			return have[i] > want[i]
+		}
+	}
+	return true
+}
+
+// parseVersion zerlegt "0.17.3" (auch "0.17.3-dev") in seine drei Teile
+func parseVersion(version string) ([3]int, bool) {
+	var nums [3]int
+	parts := strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3)
+	if len(parts) != 3 {
+		return nums, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nums, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
+
+// Feature names for Supports
+const (
+	FeatureVersionJSON = "version-json" // restic version --json
+	FeatureRepoV2      = "repo-v2"      // Repository-Format v2 (Kompression, migrate upgrade_repo_v2)
+	FeatureIncludeFile = "include-file" // restore --include-file
+	FeatureFromRepo    = "from-repo"    // --from-repo und RESTIC_FROM_PASSWORD (vorher --repo2)
+)
+
+// featureVersions nennt die erste restic-Version mit einem Feature
+var featureVersions = map[string][3]int{
+	FeatureVersionJSON: {0, 17, 0},
+	FeatureRepoV2:      {0, 14, 0},
+	FeatureIncludeFile: {0, 17, 0},
+	FeatureFromRepo:    {0, 14, 0},
+}
+
+// Supports reports whether this restic version has feature
+func (v VersionInfo) Supports(feature string) bool {
+	min, ok := featureVersions[feature]
+	if !ok {
+		return false
+	}
+	return v.AtLeast(min[0], min[1], min[2])
+}
+
+// versionText matcht "restic 0.16.4 compiled with go1.21.6 on windows/amd64"
+var versionText = regexp.MustCompile(`restic (\S+) compiled with (\S+) on ([^/\s]+)/(\S+)`)
+
+// DetectVersion asks restic for its version, preferring the JSON output of
+// newer releases and falling back to parsing the text output
+func DetectVersion(r CommandRunner) (VersionInfo, error) {
+	if out, err := r.Run(Repo{}, []string{"version", "--json"}); err == nil {
+		if v, ok := parseVersionJSON(out); ok {
+			return v, nil
+		}
+	}
+	out, err := r.Run(Repo{}, []string{"version"})
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	m := versionText.FindStringSubmatch(out)
+	if m == nil {
+		return VersionInfo{}, fmt.Errorf("unrecognized restic version output")
+	}
+	return VersionInfo{Version: m[1], GoVersion: m[2], GoOS: m[3], GoArch: m[4]}, nil
+}
+
+// parseVersionJSON: ältere Versionen ignorieren --json und geben Text aus
+func parseVersionJSON(out string) (VersionInfo, bool) {
+	var v VersionInfo
+	start := strings.Index(out, "{")
+	if start < 0 || json.Unmarshal([]byte(out[start:]), &v) != nil || v.Version == "" {
+		return v, false
+	}
+	return v, true
+}
@@ -0,0 +1,61 @@
+package restic
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// readDataSubset erkennt die drei Formen von --read-data-subset:
+// "n/t" (Teil n von t), "x%" und eine Größe wie "500M" oder "2G"
+var (
+	subsetFraction = regexp.MustCompile(`^(\d+)/(\d+)$`)
+	subsetPercent  = regexp.MustCompile(`^(\d+(?:\.\d+)?)%$`)
+	subsetSize     = regexp.MustCompile(`^\d+[KMGTkmgt]$`)
+)
+
+// ValidateReadDataSubset checks a spec for restic check --read-data-subset:
+// "n/t" with 1 <= n <= t, a percentage in (0, 100] or a size such as "2G"
+func ValidateReadDataSubset(spec string) error {
+	spec = strings.TrimSpace(spec)
+	if m := subsetFraction.FindStringSubmatch(spec); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		t, _ := strconv.Atoi(m[2])
+		if n < 1 || n > t {
+			return fmt.Errorf("invalid read-data subset %q: expected n/t with 1 <= n <= t", spec)
+		}
+		return nil
+	}
+	if m := subsetPercent.FindStringSubmatch(spec); m != nil {
+		p, _ := strconv.ParseFloat(m[1], 64)
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("invalid read-data subset %q: percentage must be between 0 and 100", spec)
+		}
+		return nil
+	}
+	if subsetSize.MatchString(spec) && !strings.HasPrefix(spec, "0") {
+		return nil
+	}
+	return fmt.Errorf("invalid read-data subset %q (examples: 10%%, 1/12, 2G)", spec)
+}
+
+// CheckArgs builds the arguments for restic check; an empty subset checks
+// only the repository structure without reading pack data
+func CheckArgs(subset string) ([]string, error) {
+	subset = strings.TrimSpace(subset)
+	if subset == "" {
+		return []string{"check"}, nil
+	}
+	if err := ValidateReadDataSubset(subset); err != nil {
+		return nil, err
+	}
+	return []string{"check", "--read-data-subset=" + subset}, nil
+}
+
+// ParseCheckLine turns one line of "restic check" output (stdout or stderr)
+// into progress, like ParsePruneLine. Progress bars report e.g. the share of
+// packs read with --read-data-subset.
+func ParseCheckLine(line string) (PhaseProgress, bool) {
+	return parsePhaseLine("check", line)
+}
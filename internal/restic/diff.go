@@ -0,0 +1,65 @@
+package restic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DiffChange ist eine "change"-Meldung von restic diff --json. Modifier ist
+// "+" (neu), "-" (entfernt), "M" (Inhalt), "T" (Typ) oder "U" (Metadaten)
+type DiffChange struct {
+	Path     string `json:"path"`
+	Modifier string `json:"modifier"`
+}
+
+// DiffCounts zählt hinzugefügte bzw. entfernte Einträge
+type DiffCounts struct {
+	Files int    `json:"files"`
+	Dirs  int    `json:"dirs"`
+	Bytes uint64 `json:"bytes"`
+}
+
+// DiffResult fasst die Ausgabe von restic diff --json zusammen
+type DiffResult struct {
+	From         string       `json:"from"` // vollständige Snapshot-IDs
+	To           string       `json:"to"`
+	Changes      []DiffChange `json:"changes"`
+	ChangedFiles int          `json:"changedFiles"`
+	Added        DiffCounts   `json:"added"`
+	Removed      DiffCounts   `json:"removed"`
+}
+
+// ParseDiff reads the line-wise JSON of "restic diff --json": one "change"
+// message per path followed by a final "statistics" message
+func ParseDiff(out string) (DiffResult, error) {
+	res := DiffResult{Changes: []DiffChange{}}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var msg struct {
+			MessageType  string     `json:"message_type"`
+			Path         string     `json:"path"`
+			Modifier     string     `json:"modifier"`
+			Source       string     `json:"source_snapshot"`
+			Target       string     `json:"target_snapshot"`
+			ChangedFiles int        `json:"changed_files"`
+			Added        DiffCounts `json:"added"`
+			Removed      DiffCounts `json:"removed"`
+		}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			return DiffResult{}, fmt.Errorf("failed to parse diff output")
+		}
+		switch msg.MessageType {
+		case "change":
+			res.Changes = append(res.Changes, DiffChange{Path: msg.Path, Modifier: msg.Modifier})
+		case "statistics":
+			res.From, res.To = msg.Source, msg.Target
+			res.ChangedFiles = msg.ChangedFiles
+			res.Added, res.Removed = msg.Added, msg.Removed
+		}
+	}
+	return res, nil
+}
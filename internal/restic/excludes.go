@@ -0,0 +1,87 @@
+package restic
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ExcludeIssue beschreibt ein Exclude-Muster, das vermutlich nicht wie
+// gewollt greift, samt Vorschlag
+type ExcludeIssue struct {
+	Pattern    string   `json:"pattern"`
+	Normalized string   `json:"normalized"` // leer = Muster entfernen
+	Reasons    []string `json:"reasons"`
+}
+
+// NormalizeExclude returns the form of an exclude pattern restic matches
+// as intended, with the reasons for each change. Surrounding spaces and
+// trailing separators are removed ("C:\temp\" never matches a directory),
+// a leading "~" is expanded and on Windows backslashes become "/". On
+// other systems a backslash is restic's escape character and is kept.
+func NormalizeExclude(pattern string) (string, []string) {
+	var reasons []string
+	p := strings.TrimSpace(pattern)
+	if p != pattern {
+		reasons = append(reasons, "leading or trailing spaces")
+	}
+	if p == "" {
+		return "", append(reasons, "empty pattern")
+	}
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = home + p[1:]
+			reasons = append(reasons, "restic does not expand ~")
+		}
+	}
+	if runtime.GOOS == "windows" && strings.Contains(p, `\`) {
+		p = strings.ReplaceAll(p, `\`, "/")
+		reasons = append(reasons, "backslashes replaced with /")
+	}
+	separators := "/"
+	if runtime.GOOS == "windows" {
+		separators = `/\`
+	}
+	if trimmed := strings.TrimRight(p, separators); trimmed != p && trimmed != "" && !strings.HasSuffix(trimmed, ":") {
+		p = trimmed
+		reasons = append(reasons, "trailing separator never matches")
+	}
+	return p, reasons
+}
+
+// CheckExcludes lists the patterns NormalizeExclude would change and
+// duplicates, in the order given
+func CheckExcludes(patterns []string) []ExcludeIssue {
+	issues := []ExcludeIssue{}
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		normalized, reasons := NormalizeExclude(pattern)
+		if normalized != "" && seen[normalized] {
+			normalized = ""
+			reasons = append(reasons, "duplicate pattern")
+		}
+		if normalized != "" {
+			seen[normalized] = true
+		}
+		if len(reasons) > 0 {
+			issues = append(issues, ExcludeIssue{Pattern: pattern, Normalized: normalized, Reasons: reasons})
+		}
+	}
+	return issues
+}
+
+// NormalizeExcludes applies NormalizeExclude to every pattern and drops
+// empty and duplicate ones
+func NormalizeExcludes(patterns []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		p, _ := NormalizeExclude(pattern)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package restic
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// uriVarPattern erkennt ${VAR} und %VAR% in Repository-URIs, unabhängig vom
+// Betriebssystem, damit eine Konfiguration auf mehreren Rechnern funktioniert
+var uriVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|%([A-Za-z_][A-Za-z0-9_]*)%`)
+
+// ExpandURI replaces ${VAR} and %VAR% in a repository URI with values from
+// the process environment. A variable that is not set is an error rather
+// than an empty string, so a typo never points restic at the wrong place.
+func ExpandURI(uri string) (string, error) {
+	var missing string
+	expanded := uriVarPattern.ReplaceAllStringFunc(uri, func(ref string) string {
+		m := uriVarPattern.FindStringSubmatch(ref)
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("repository URI uses environment variable %s, which is not set", missing)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,78 @@
+package restic
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ProtectedTag marks snapshots that forget must never remove
+const ProtectedTag = "protected"
+
+// ForgetPolicy describes which snapshots "restic forget" keeps
+type ForgetPolicy struct {
+	KeepLast    int      `json:"keepLast"`
+	KeepHourly  int      `json:"keepHourly"`
+	KeepDaily   int      `json:"keepDaily"`
+	KeepWeekly  int      `json:"keepWeekly"`
+	KeepMonthly int      `json:"keepMonthly"`
+	KeepYearly  int      `json:"keepYearly"`
+	KeepWithin  string   `json:"keepWithin"` // e.g. "30d" or "1y6m"
+	KeepTags    []string `json:"keepTags"`
+}
+
+// IsEmpty reports whether the policy would keep nothing explicitly,
+// in which case restic refuses to forget anything
+func (p ForgetPolicy) IsEmpty() bool {
+	return p.KeepLast == 0 && p.KeepHourly == 0 && p.KeepDaily == 0 &&
+		p.KeepWeekly == 0 && p.KeepMonthly == 0 && p.KeepYearly == 0 &&
+		p.KeepWithin == "" && len(p.KeepTags) == 0
+}
+
+// Args converts the policy into "restic forget" flags
+func (p ForgetPolicy) Args() ([]string, error) {
+	var args []string
+	counts := []struct {
+		flag string
+		n    int
+	}{
+		{"--keep-last", p.KeepLast},
+		{"--keep-hourly", p.KeepHourly},
+		{"--keep-daily", p.KeepDaily},
+		{"--keep-weekly", p.KeepWeekly},
+		{"--keep-monthly", p.KeepMonthly},
+		{"--keep-yearly", p.KeepYearly},
+	}
+	for _, c := range counts {
+		if c.n < 0 {
+			return nil, fmt.Errorf("%s must not be negative", c.flag)
+		}
+		if c.n > 0 {
+			args = append(args, c.flag, strconv.Itoa(c.n))
+		}
+	}
+	if p.KeepWithin != "" {
+		args = append(args, "--keep-within", p.KeepWithin)
+	}
+	for _, tag := range p.KeepTags {
+		args = append(args, "--keep-tag", tag)
+	}
+	// Protected snapshots are kept regardless of the policy
+	args = append(args, "--keep-tag", ProtectedTag)
+	return args, nil
+}
+
+// ForgetGroup ist ein Eintrag der Ausgabe von restic forget --json
+type ForgetGroup struct {
+	Tags    []string     `json:"tags"`
+	Host    string       `json:"host"`
+	Paths   []string     `json:"paths"`
+	Keep    []Snapshot   `json:"keep"`
+	Remove  []Snapshot   `json:"remove"`
+	Reasons []KeepReason `json:"reasons"`
+}
+
+// KeepReason erklärt, warum ein Snapshot behalten wird
+type KeepReason struct {
+	Snapshot Snapshot `json:"snapshot"`
+	Matches  []string `json:"matches"`
+}
@@ -0,0 +1,97 @@
+package restic
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"restic-gui/internal/i18n"
+)
+
+// ErrorRule maps a substring of restic's output to a user-friendly message.
+// Bundled rules reference a locale Key; user rules may give a literal Message.
+type ErrorRule struct {
+	Match   string `json:"match"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// text returns the rule's message in the active language
+func (r ErrorRule) text() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return i18n.T(r.Key)
+}
+
+//go:embed friendly_errors.json
+var bundledErrorRules []byte
+
+var (
+	rulesMu    sync.RWMutex
+	errorRules = mustParseRules(bundledErrorRules)
+)
+
+func mustParseRules(data []byte) []ErrorRule {
+	rules, err := parseRules(data)
+	if err != nil {
+		panic("invalid bundled friendly_errors.json: " + err.Error())
+	}
+	return rules
+}
+
+func parseRules(data []byte) ([]ErrorRule, error) {
+	var rules []ErrorRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	for i, r := range rules {
+		if strings.TrimSpace(r.Match) == "" || (r.Message == "" && r.Key == "") {
+			return nil, fmt.Errorf("rule %d needs a match and a message or key", i+1)
+		}
+		rules[i].Match = strings.ToLower(r.Match)
+	}
+	return rules, nil
+}
+
+// LoadErrorRules reads user-defined rules from a JSON file with the same
+// format as the bundled friendly_errors.json. They are checked before the
+// bundled rules, so users can both add and override translations.
+// A missing file is not an error.
+func LoadErrorRules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	user, err := parseRules(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	bundled := mustParseRules(bundledErrorRules)
+	rulesMu.Lock()
+	errorRules = append(user, bundled...)
+	rulesMu.Unlock()
+	return nil
+}
+
+// friendlyError translates technical restic errors into user-friendly messages
+func friendlyError(raw string) string {
+	lower := strings.ToLower(raw)
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	for _, r := range errorRules {
+		if strings.Contains(lower, r.Match) {
+			return r.text()
+		}
+	}
+	if raw == "" {
+		return i18n.T("error.unknown")
+	}
+	return raw
+}
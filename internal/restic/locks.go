@@ -0,0 +1,43 @@
+package restic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StaleLockAge: restic erneuert die Locks laufender Befehle alle 5 Minuten
+// und hält einen Lock nach 30 Minuten ohne Erneuerung für verwaist
+const StaleLockAge = 30 * time.Minute
+
+// Lock ist die Ausgabe von restic cat lock <id>
+type Lock struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"` // letzte Erneuerung
+	Exclusive bool      `json:"exclusive"`
+	Hostname  string    `json:"hostname"`
+	Username  string    `json:"username"`
+	PID       int       `json:"pid"`
+}
+
+// ParseLock parses the output of "restic cat lock <id>"
+func ParseLock(id, out string) (Lock, error) {
+	var l Lock
+	if err := json.Unmarshal([]byte(out), &l); err != nil {
+		return Lock{}, fmt.Errorf("failed to parse lock %s", id)
+	}
+	l.ID = id
+	return l, nil
+}
+
+// ParseIDs splits the output of "restic list <type>" into IDs
+func ParseIDs(out string) []string {
+	var ids []string
+	for _, line := range strings.Split(out, "\n") {
+		if id := strings.TrimSpace(line); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,28 @@
+package restic
+
+import "strings"
+
+// Migration ist eine von "restic migrate" angebotene Formatänderung
+type Migration struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ParseMigrations liest die Ausgabe von "restic migrate" ohne Argumente:
+//
+//	available migrations:
+//	  upgrade_repo_v2	upgrade a repository to version 2
+func ParseMigrations(out string) []Migration {
+	var migrations []Migration
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			continue // Überschrift oder Leerzeile
+		}
+		name, desc, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if name == "" {
+			continue
+		}
+		migrations = append(migrations, Migration{Name: name, Description: strings.TrimSpace(desc)})
+	}
+	return migrations
+}
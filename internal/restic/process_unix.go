@@ -0,0 +1,73 @@
+//go:build !windows
+
+package restic
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// niceLevel liefert den nice-Wert einer Priorität, 0 = unverändert
+func niceLevel(p Priority) int {
+	switch p {
+	case PriorityLow:
+		return 10
+	case PriorityIdle:
+		return 19
+	}
+	return 0
+}
+
+// prepareProcess startet restic bei niedriger Priorität über "nice", damit
+// schon der erste Thread den nice-Wert hat und alle weiteren ihn erben.
+// Ohne nice im PATH stellt applyPriority die Threads nachträglich um.
+func prepareProcess(cmd *exec.Cmd, p Priority) {
+	n := niceLevel(p)
+	if n == 0 {
+		return
+	}
+	nicePath, err := exec.LookPath("nice")
+	if err != nil {
+		return
+	}
+	cmd.Args = append([]string{"nice", "-n", strconv.Itoa(n), cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = nicePath
+}
+
+// applyPriority ist der Weg ohne nice: setpriority wirkt unter Linux nur auf
+// einen Thread, daher wird jeder Thread aus /proc/<pid>/task umgestellt.
+// Threads, die restic danach anlegt, erben den Wert. Ein Fehler wird
+// protokolliert, restic läuft dann mit normaler Priorität weiter.
+func applyPriority(cmd *exec.Cmd, p Priority) {
+	n := niceLevel(p)
+	if n == 0 || (len(cmd.Args) > 0 && cmd.Args[0] == "nice") {
+		return
+	}
+	if err := reniceProcess(cmd.Process.Pid, n); err != nil {
+		log.Printf("restic: could not lower the priority of process %d: %v", cmd.Process.Pid, err)
+	}
+}
+
+// reniceProcess setzt den nice-Wert aller Threads von pid; ohne /proc
+// (z.B. macOS) gilt setpriority ohnehin für den ganzen Prozess
+func reniceProcess(pid, nice int) error {
+	tids := []int{pid}
+	if entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/task", pid)); err == nil {
+		tids = tids[:0]
+		for _, e := range entries {
+			if tid, err := strconv.Atoi(e.Name()); err == nil {
+				tids = append(tids, tid)
+			}
+		}
+	}
+	for _, tid := range tids {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, nice); err != nil {
+			return fmt.Errorf("thread %d: %w", tid, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package restic
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// Prioritätsklassen für CreateProcess
+const (
+	belowNormalPriorityClass = 0x00004000
+	idlePriorityClass        = 0x00000040
+)
+
+// prepareProcess versteckt das Konsolenfenster und setzt die Prioritätsklasse
+func prepareProcess(cmd *exec.Cmd, p Priority) {
+	attr := &syscall.SysProcAttr{HideWindow: true}
+	switch p {
+	case PriorityLow:
+		attr.CreationFlags |= belowNormalPriorityClass
+	case PriorityIdle:
+		attr.CreationFlags |= idlePriorityClass
+	}
+	cmd.SysProcAttr = attr
+}
+
+// applyPriority: unter Windows bereits beim Start über CreationFlags gesetzt
+func applyPriority(cmd *exec.Cmd, p Priority) {}
@@ -0,0 +1,132 @@
+package restic
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PruneEstimate fasst die Ausgabe von "restic prune --dry-run" zusammen
+type PruneEstimate struct {
+	ReclaimableBytes uint64 `json:"reclaimableBytes"` // "total prune"
+	PacksToDelete    int    `json:"packsToDelete"`
+	PacksToRepack    int    `json:"packsToRepack"`
+	UnusedAfter      uint64 `json:"unusedAfter"` // "unused size after prune"
+}
+
+var (
+	pruneLine  = regexp.MustCompile(`^\s*(to repack|to delete|total prune|unused size after prune):\s+(.+)$`)
+	pruneBlobs = regexp.MustCompile(`blobs / ([\d.]+ [KMGT]?i?B)`)
+	prunePacks = regexp.MustCompile(`^(\d+) packs`)
+)
+
+// ParsePruneDryRun liest die Textausgabe von "restic prune --dry-run":
+//
+//	to repack:           836 blobs / 14.187 MiB
+//	total prune:           4 blobs / 2.555 KiB
+//	unused size after prune: 1.736 GiB (3.33% of remaining size)
+//	to repack:                  4 packs
+//	to delete:                  0 packs
+//
+// "to repack"/"to delete" kommen zweimal vor, einmal mit Blobs, einmal mit Packs.
+func ParsePruneDryRun(out string) PruneEstimate {
+	var est PruneEstimate
+	for _, line := range strings.Split(out, "\n") {
+		m := pruneLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		key, value := m[1], strings.TrimSpace(m[2])
+		if p := prunePacks.FindStringSubmatch(value); p != nil {
+			n, _ := strconv.Atoi(p[1])
+			switch key {
+			case "to repack":
+				est.PacksToRepack = n
+			case "to delete":
+				est.PacksToDelete = n
+			}
+			continue
+		}
+		switch key {
+		case "total prune":
+			if b := pruneBlobs.FindStringSubmatch(value); b != nil {
+				est.ReclaimableBytes = parseBytes(b[1])
+			}
+		case "unused size after prune":
+			size, _, _ := strings.Cut(value, " (")
+			est.UnusedAfter = parseBytes(size)
+		}
+	}
+	return est
+}
+
+// byteUnits sind die Einheiten von restics ui.FormatBytes
+var byteUnits = map[string]float64{
+	"B":   1,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+// parseBytes wandelt z.B. "14.187 MiB" in Bytes um; unbekanntes ergibt 0
+func parseBytes(s string) uint64 {
+	num, unit, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return 0
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	mult, known := byteUnits[unit]
+	if err != nil || !known {
+		return 0
+	}
+	return uint64(f * mult)
+}
+
+// PhaseProgress wird während DeleteSnapshot als "forget:progress" bzw.
+// "prune:progress" und während einer Prüfung als "check:progress" gesendet
+type PhaseProgress struct {
+	Phase   string  `json:"phase"`   // "forget", "prune" oder "check"
+	Percent float64 `json:"percent"` // 0–100, -1 = unbekannt
+	Message string  `json:"message"` // aktueller Schritt, z.B. "repacking packs"
+}
+
+// progressBar erkennt restics Fortschrittszeilen, z.B.
+// "[0:02] 45.00%  9 / 20 packs processed"
+var progressBar = regexp.MustCompile(`^\[[\d:]+\]\s+([\d.]+)%\s*(.*)$`)
+
+// ParsePruneLine turns one line of "restic prune" output into progress.
+// Progress bars yield their percentage; other lines such as "repacking
+// packs" start a new step with unknown percentage. Empty lines yield false.
+func ParsePruneLine(line string) (PhaseProgress, bool) {
+	return parsePhaseLine("prune", line)
+}
+
+// parsePhaseLine wertet eine Textzeile von prune oder check aus
+func parsePhaseLine(phase, line string) (PhaseProgress, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return PhaseProgress{}, false
+	}
+	if m := progressBar.FindStringSubmatch(line); m != nil {
+		p, _ := strconv.ParseFloat(m[1], 64)
+		return PhaseProgress{Phase: phase, Percent: p, Message: m[2]}, true
+	}
+	return PhaseProgress{Phase: phase, Percent: -1, Message: line}, true
+}
+
+// progressFPS: restic zeigt Fortschrittsbalken ohne Terminal nur, wenn
+// RESTIC_PROGRESS_FPS gesetzt ist; eine Zeile pro Sekunde genügt der UI
+const progressFPS = "1"
+
+// WithProgress returns a copy of repo that makes restic print progress bars
+// even though its output is not a terminal
+func (repo Repo) WithProgress() Repo {
+	env := make(map[string]string, len(repo.Env)+1)
+	for k, v := range repo.Env {
+		env[k] = v
+	}
+	env["RESTIC_PROGRESS_FPS"] = progressFPS
+	repo.Env = env
+	return repo
+}
@@ -0,0 +1,116 @@
+// Package restictest provides a fake restic.CommandRunner for tests.
+package restictest
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	"restic-gui/internal/restic"
+)
+
+// Call records a single invocation of the fake runner
+type Call struct {
+	Repo restic.Repo
+	Args []string
+}
+
+// Response is the scripted result for a subcommand
+type Response struct {
+	Output string   // returned by Run, written to w by RunToWriter
+	Lines  []string // passed to onLine by RunWithProgress
+	Err    error
+}
+
+// FakeRunner records all calls and answers them from Responses, keyed by
+// the restic subcommand (first argument, e.g. "backup" or "snapshots").
+// Unknown subcommands succeed with empty output.
+type FakeRunner struct {
+	mu        sync.Mutex
+	Responses map[string]Response
+	Calls     []Call
+	Cancelled int
+}
+
+var _ restic.CommandRunner = (*FakeRunner)(nil)
+
+// NewFakeRunner returns a fake with no scripted responses
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{Responses: map[string]Response{}}
+}
+
+func (f *FakeRunner) respond(repo restic.Repo, args []string) Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, Call{Repo: repo, Args: append([]string(nil), args...)})
+	if len(args) == 0 {
+		return Response{}
+	}
+	return f.Responses[args[0]]
+}
+
+func (f *FakeRunner) Run(repo restic.Repo, args []string) (string, error) {
+	resp := f.respond(repo, args)
+	return resp.Output, resp.Err
+}
+
+func (f *FakeRunner) RunContext(ctx context.Context, repo restic.Repo, args []string) (string, error) {
+	resp := f.respond(repo, args)
+	if ctx.Err() != nil {
+		return "", restic.ErrCancelled
+	}
+	return resp.Output, resp.Err
+}
+
+func (f *FakeRunner) RunWithProgress(repo restic.Repo, args []string, onLine func(string)) error {
+	resp := f.respond(repo, args)
+	for _, line := range resp.Lines {
+		onLine(line)
+	}
+	return resp.Err
+}
+
+func (f *FakeRunner) RunWithProgressContext(ctx context.Context, repo restic.Repo, args []string, onLine func(string)) error {
+	resp := f.respond(repo, args)
+	for _, line := range resp.Lines {
+		if ctx.Err() != nil {
+			return restic.ErrCancelled
+		}
+		onLine(line)
+	}
+	return resp.Err
+}
+
+func (f *FakeRunner) RunToWriter(repo restic.Repo, args []string, w io.Writer) error {
+	resp := f.respond(repo, args)
+	if _, err := io.WriteString(w, resp.Output); err != nil {
+		return err
+	}
+	return resp.Err
+}
+
+func (f *FakeRunner) Cancel() {
+	f.mu.Lock()
+	f.Cancelled++
+	f.mu.Unlock()
+}
+
+func (f *FakeRunner) ResticPath() string {
+	return "restic"
+}
+
+// LastCall returns the most recent call, or an empty Call if there was none
+func (f *FakeRunner) LastCall() Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.Calls) == 0 {
+		return Call{}
+	}
+	return f.Calls[len(f.Calls)-1]
+}
+
+// CommandLine joins the args of c for easy comparison in tests
+func (c Call) CommandLine() string {
+	return strings.Join(c.Args, " ")
+}
@@ -0,0 +1,107 @@
+package restic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GroupKey beschreibt, wonach restic snapshots --group-by gruppiert hat;
+// nicht gruppierte Felder bleiben leer
+type GroupKey struct {
+	Hostname string   `json:"hostname"`
+	Paths    []string `json:"paths"`
+	Tags     []string `json:"tags"`
+}
+
+// SnapshotGroup ist eine Gruppe der Ausgabe von restic snapshots --group-by
+type SnapshotGroup struct {
+	GroupKey  GroupKey   `json:"group_key"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// ValidateGroupBy checks a --group-by value such as "host,tags"
+func ValidateGroupBy(groupBy string) error {
+	for _, field := range strings.Split(groupBy, ",") {
+		switch strings.TrimSpace(field) {
+		case "host", "paths", "tags":
+		default:
+			return fmt.Errorf("invalid group-by field %q (allowed: host, paths, tags)", field)
+		}
+	}
+	return nil
+}
+
+// ParseSnapshots parses the output of "restic snapshots --json". An empty
+// repository yields an empty slice, whether restic prints "[]", "null" or nothing.
+func ParseSnapshots(out string) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+	if isEmptyJSON(out) {
+		return snapshots, nil
+	}
+	if err := json.Unmarshal([]byte(out), &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot data")
+	}
+	if snapshots == nil {
+		snapshots = []Snapshot{}
+	}
+	return snapshots, nil
+}
+
+// ParseSnapshotBriefs parses "restic snapshots --json" like ParseSnapshots,
+// keeping only the fields of SnapshotBrief
+func ParseSnapshotBriefs(out string) ([]SnapshotBrief, error) {
+	briefs := []SnapshotBrief{}
+	if isEmptyJSON(out) {
+		return briefs, nil
+	}
+	if err := json.Unmarshal([]byte(out), &briefs); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot data")
+	}
+	if briefs == nil {
+		briefs = []SnapshotBrief{}
+	}
+	return briefs, nil
+}
+
+// isEmptyJSON: leere Ausgabe oder "null"
+func isEmptyJSON(out string) bool {
+	out = strings.TrimSpace(out)
+	return out == "" || out == "null"
+}
+
+// ParseSnapshotGroups parses the output of "restic snapshots --json", with or
+// without --group-by. A flat list becomes a single group with an empty key.
+func ParseSnapshotGroups(out string) ([]SnapshotGroup, error) {
+	if isEmptyJSON(out) {
+		return []SnapshotGroup{}, nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(out), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot data")
+	}
+	if len(raw) == 0 {
+		return []SnapshotGroup{}, nil
+	}
+
+	// Gruppierte Ausgabe erkennt man am Feld group_key
+	var probe struct {
+		GroupKey *GroupKey `json:"group_key"`
+	}
+	if err := json.Unmarshal(raw[0], &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot data")
+	}
+	if probe.GroupKey != nil {
+		var groups []SnapshotGroup
+		if err := json.Unmarshal([]byte(out), &groups); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot data")
+		}
+		return groups, nil
+	}
+
+	snapshots, err := ParseSnapshots(out)
+	if err != nil {
+		return nil, err
+	}
+	return []SnapshotGroup{{Snapshots: snapshots}}, nil
+}
@@ -26,6 +26,65 @@ type BackupProgress struct {
 	TotalBytesProc  uint64  `json:"total_bytes_processed"`
 	TotalDuration   float64 `json:"total_duration"`
 	SnapshotID      string  `json:"snapshot_id"`
+	DryRun          bool    `json:"dry_run"` // backup --dry-run, es wurde nichts gespeichert
+	// Von uns berechnet (AddThroughput), nicht Teil der restic-Ausgabe
+	BytesPerSecond float64 `json:"bytes_per_second,omitempty"`
+	FilesPerSecond float64 `json:"files_per_second,omitempty"`
+}
+
+// AddThroughput berechnet den Durchsatz einer "summary"-Meldung
+func (p *BackupProgress) AddThroughput() {
+	if p.TotalDuration <= 0 {
+		return
+	}
+	p.BytesPerSecond = float64(p.TotalBytesProc) / p.TotalDuration
+	p.FilesPerSecond = float64(p.TotalFilesProc) / p.TotalDuration
+}
+
+// BackupStatus ist die schlanke Fortschrittsmeldung für das häufige
+// "backup:progress"-Event; enthält nur die Felder, die die UI anzeigt
+type BackupStatus struct {
+	MessageType      string  `json:"message_type"`
+	SecondsElapsed   float64 `json:"seconds_elapsed"`
+	SecondsRemaining float64 `json:"seconds_remaining"`
+	PercentDone      float64 `json:"percent_done"`
+	TotalFiles       uint64  `json:"total_files"`
+	FilesDone        uint64  `json:"files_done"`
+	TotalBytes       uint64  `json:"total_bytes"`
+	BytesDone        uint64  `json:"bytes_done"`
+	CurrentFile      string  `json:"current_file"`
+	// Von der App ergänzt: Quellordner, unter denen die aktuellen Dateien liegen
+	CurrentSources []string `json:"current_sources,omitempty"`
+}
+
+// Status reduziert eine "status"-Meldung auf BackupStatus
+func (p BackupProgress) Status() BackupStatus {
+	s := BackupStatus{
+		MessageType:      p.MessageType,
+		SecondsElapsed:   p.SecondsElapsed,
+		SecondsRemaining: p.SecondsRemaining,
+		PercentDone:      p.PercentDone,
+		TotalFiles:       p.TotalFiles,
+		FilesDone:        p.FilesDone,
+		TotalBytes:       p.TotalBytes,
+		BytesDone:        p.BytesDone,
+	}
+	if len(p.CurrentFiles) > 0 {
+		s.CurrentFile = p.CurrentFiles[0]
+	}
+	return s
+}
+
+// VerboseStatus ist eine "verbose_status"-Meldung von backup --json --verbose
+// und beschreibt eine einzelne verarbeitete Datei
+type VerboseStatus struct {
+	MessageType  string  `json:"message_type"`
+	Action       string  `json:"action"` // "new", "unchanged", "modified", "scan_finished"
+	Item         string  `json:"item"`
+	Duration     float64 `json:"duration"`
+	DataSize     uint64  `json:"data_size"`
+	MetadataSize uint64  `json:"metadata_size"`
+	TotalFiles   uint64  `json:"total_files"`
 }
 
 // Snapshot repräsentiert einen restic Snapshot
@@ -37,6 +96,43 @@ type Snapshot struct {
 	Username string   `json:"username"`
 	Paths    []string `json:"paths"`
 	Tags     []string `json:"tags"`
+	Original string   `json:"original,omitempty"` // ID vor dem ersten Umschreiben (tag, rewrite)
+	Note     string   `json:"note,omitempty"`     // lokal gespeichert, nicht von restic
+}
+
+// SnapshotBrief enthält nur die Felder für eine schnelle Übersicht; Pfade
+// und Tags können bei tausenden Snapshots einen großen Teil der Daten ausmachen
+type SnapshotBrief struct {
+	ID       string `json:"id"`
+	ShortID  string `json:"short_id"`
+	Time     string `json:"time"`
+	Hostname string `json:"hostname"`
+}
+
+// IsProtected: Snapshots mit ProtectedTag dürfen nicht gelöscht werden
+func (s Snapshot) IsProtected() bool {
+	for _, t := range s.Tags {
+		if t == ProtectedTag {
+			return true
+		}
+	}
+	return false
+}
+
+// SnapshotStats ist die Ausgabe von restic stats --json <snapshot>
+type SnapshotStats struct {
+	SnapshotID     string `json:"snapshot_id"`
+	TotalSize      uint64 `json:"total_size"`
+	TotalFileCount uint64 `json:"total_file_count"`
+}
+
+// RepoStats ist die Ausgabe von restic stats --json --mode raw-data
+type RepoStats struct {
+	TotalSize             uint64  `json:"total_size"`
+	TotalUncompressedSize uint64  `json:"total_uncompressed_size"`
+	CompressionRatio      float64 `json:"compression_ratio"`
+	TotalBlobCount        uint64  `json:"total_blob_count"`
+	SnapshotsCount        int     `json:"snapshots_count"`
 }
 
 // RestoreProgress ist die JSON-Ausgabe von restic restore --json
@@ -53,20 +149,62 @@ type RestoreProgress struct {
 	BytesSkipped     uint64  `json:"bytes_skipped"`
 }
 
+// ErrorUpdate ist eine Fehlermeldung von restic --json (message_type "error"),
+// z.B. eine Datei, die sich nicht wiederherstellen oder verifizieren ließ
+type ErrorUpdate struct {
+	MessageType string `json:"message_type"`
+	Error       struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	During string `json:"during"`
+	Item   string `json:"item"`
+}
+
 // BackupJob definiert einen Backup-Auftrag
 type BackupJob struct {
 	RepoID      string   `json:"repoId"`
 	SourcePaths []string `json:"sourcePaths"`
 	Excludes    []string `json:"excludes"`
 	Tags        []string `json:"tags"`
+	// Optional: Snapshot-Zeit im Format "YYYY-MM-DD HH:MM:SS" (--time)
+	SnapshotTime string `json:"snapshotTime"`
+	// Optional: Eltern-Snapshot für die Änderungserkennung (--parent)
+	ParentSnapshot string `json:"parentSnapshot"`
+	// Fehlende Quellordner überspringen statt das Backup abzulehnen
+	SkipMissing bool `json:"skipMissing"`
+	// Je Quellordner dessen Namen als Tag setzen, z.B. "projekt-a" für D:\Projekte\projekt-a
+	TagByFolder bool `json:"tagByFolder"`
 }
 
+// SnapshotTimeLayout ist das von restic backup --time erwartete Format
+const SnapshotTimeLayout = "2006-01-02 15:04:05"
+
 // FileNode repräsentiert eine Datei oder einen Ordner im Snapshot (restic ls --json)
 type FileNode struct {
 	StructType string `json:"struct_type"` // "node" oder "snapshot"
 	Name       string `json:"name"`
-	Type       string `json:"type"` // "file" oder "dir"
+	Type       string `json:"type"` // "file", "dir", "symlink", "dev", "chardev", "fifo" oder "socket"
 	Path       string `json:"path"`
 	Size       uint64 `json:"size"`
 	MTime      string `json:"mtime"`
+	LinkTarget string `json:"linktarget,omitempty"` // nur bei "symlink"
+}
+
+// FindResult ist ein Eintrag der Ausgabe von restic find --json
+type FindResult struct {
+	Hits     int        `json:"hits"`
+	Snapshot string     `json:"snapshot"`
+	Matches  []FileNode `json:"matches"`
+}
+
+// RepoConfig ist die Ausgabe von restic cat config
+type RepoConfig struct {
+	Version           int    `json:"version"`
+	ID                string `json:"id"`
+	ChunkerPolynomial string `json:"chunker_polynomial"`
+}
+
+// SupportsCompression: erst Repository-Format v2 kann komprimieren
+func (c RepoConfig) SupportsCompression() bool {
+	return c.Version >= 2
 }
@@ -0,0 +1,52 @@
+package restic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// LatestReleaseURL liefert die neueste restic-Version von GitHub
+const LatestReleaseURL = "https://api.github.com/repos/restic/restic/releases/latest"
+
+// LatestRelease asks GitHub for the newest restic release and returns its
+// version without the leading "v", e.g. "0.17.3"
+func LatestRelease(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, LatestReleaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "restic-gui")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("release check failed: %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to parse release information")
+	}
+	latest := strings.TrimPrefix(release.TagName, "v")
+	if latest == "" {
+		return "", fmt.Errorf("failed to parse release information")
+	}
+	return latest, nil
+}
+
+// OlderThan reports whether v is an older release than version (e.g.
+// "0.17.3"); unparsable versions are never considered outdated
+func (v VersionInfo) OlderThan(version string) bool {
+	want, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	return !v.AtLeast(want[0], want[1], want[2])
+}
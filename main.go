@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,6 +13,11 @@ import (
 var assets embed.FS
 
 func main() {
+	// Headless commands such as --run-backup exit without opening a window
+	if code, ok := runHeadless(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
@@ -27,6 +33,8 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnDomReady:       app.domReady,
+		OnBeforeClose:    app.beforeClose,
 		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
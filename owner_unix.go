@@ -0,0 +1,19 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// copyOwner überträgt uid/gid von src (von restic aus dem Snapshot gesetzt)
+// auf dst. Ohne Root-Rechte (bzw. CAP_CHOWN) schlägt das fehl; dann bleibt
+// der aktuelle Benutzer Eigentümer, wie beim Restore ohne Rechte auch.
+func copyOwner(info os.FileInfo, dst string) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	os.Lchown(dst, int(st.Uid), int(st.Gid))
+}
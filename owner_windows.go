@@ -0,0 +1,6 @@
+package main
+
+import "os"
+
+// copyOwner: unter Windows bleibt der Eigentümer beim Kopieren unverändert
+func copyOwner(info os.FileInfo, dst string) {}